@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/compare"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvers"
+)
+
+// runCompare runs the compare subcommand, which has its own flags followed by
+// the puzzles to compare, as in "wjug compare -solvers bfs,iterative 5,3,4".
+func runCompare(args []string) {
+	log.SetFlags(0)
+	log.SetPrefix("wjug compare: ")
+
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s compare [flags] [x,y,z ...]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	names := flags.String("solvers", "",
+		"comma separated solvers to compare, see -list-solvers. Every solver by default, starting with iterative")
+	random := flags.Int("random", 0, "compares this many random puzzles instead of the listed ones")
+	maxCapacity := flags.Int("max-capacity", 100, "largest capacity of the -random puzzles")
+	seed := flags.Int64("seed", 0, "seed of the -random puzzles, a random one is used if not set")
+	repeat := flags.Int("repeat", 1, "solves each puzzle this many times with each solver, averaging runtimes")
+	format := flags.String("format", string(compare.FormatText), "output format: text or json")
+	_ = flags.Parse(args)
+
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var puzzles []compare.Puzzle
+	switch {
+	case set["random"] && flags.NArg() > 0:
+		usageError("-random cannot be used along with listed puzzles")
+	case set["random"]:
+		if *random <= 0 || *maxCapacity <= 0 {
+			usageError("-random and -max-capacity must be positive")
+		}
+		if !set["seed"] {
+			*seed = time.Now().UnixNano()
+			log.Printf("comparing puzzles of -seed %d", *seed)
+		}
+		puzzles = compare.Random(*random, *maxCapacity, *seed)
+	case flags.NArg() > 0:
+		for _, arg := range flags.Args() {
+			puzzle, err := parseComparePuzzle(arg)
+			if err != nil {
+				usageError(err.Error())
+			}
+			puzzles = append(puzzles, puzzle)
+		}
+	default:
+		usageError("either -random or puzzles as in x,y,z are required")
+	}
+	if (set["max-capacity"] || set["seed"]) && !set["random"] {
+		usageError("-max-capacity and -seed require -random")
+	}
+	if f := compare.Format(*format); f != compare.FormatText && f != compare.FormatJSON {
+		usageError(fmt.Sprintf("unknown format %q", *format))
+	}
+
+	compared, err := compareSolvers(*names)
+	if err != nil {
+		usageError(err.Error())
+	}
+	report, err := compare.Compare(compare.Configuration{
+		Solvers: compared,
+		Puzzles: puzzles,
+		Repeat:  *repeat,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	output := bufio.NewWriter(os.Stdout)
+	if err = report.Write(output, compare.Format(*format)); err == nil {
+		err = output.Flush()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// compareSolvers looks up the comma separated solver names, every registered
+// solver is returned if there are none.
+func compareSolvers(names string) ([]compare.Solver, error) {
+	registrations := solvers.List()
+	if names == "" {
+		for i, r := range registrations {
+			if r.Name == "iterative" {
+				copy(registrations[1:i+1], registrations[:i])
+				registrations[0] = r
+			}
+		}
+	} else {
+		registrations = registrations[:0]
+		for _, name := range strings.Split(names, ",") {
+			r, ok := solvers.Lookup(strings.TrimSpace(name))
+			if !ok {
+				return nil, fmt.Errorf("unknown solver %q, see -list-solvers", name)
+			}
+			registrations = append(registrations, r)
+		}
+	}
+
+	compared := make([]compare.Solver, len(registrations))
+	for i, r := range registrations {
+		compared[i] = compare.Solver{Name: r.Name, Solver: adapt(r).solver}
+	}
+	return compared, nil
+}
+
+// parseComparePuzzle parses a puzzle as in "5,3,4".
+func parseComparePuzzle(arg string) (compare.Puzzle, error) {
+	fields := strings.Split(arg, ",")
+	if len(fields) != 3 {
+		return compare.Puzzle{}, fmt.Errorf("invalid puzzle %q, x,y,z was expected", arg)
+	}
+	var numbers [3]int
+	for i, field := range fields {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return compare.Puzzle{}, fmt.Errorf("invalid puzzle %q, x,y,z was expected", arg)
+		}
+		numbers[i] = n
+	}
+	return compare.Puzzle{X: numbers[0], Y: numbers[1], Z: numbers[2]}, nil
+}
@@ -1,30 +1,616 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
-	"github.com/nacho692/live-free-or-die-jugging/pkg/iterative"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/batch"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/dijkstra"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/euclid"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/generate"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/graph"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/paths"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvers"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/table"
 )
 
 func main() {
 
+	if len(os.Args) > 1 && os.Args[1] == "compare" {
+		runCompare(os.Args[2:])
+		return
+	}
+
 	silent := flag.Bool("s", false, "silences most output so only the solution is printed")
+	multi := flag.Bool("n", false, "asks for the number of jugs, allowing more than two")
+	amounts := flag.Bool("a", false, "asks for the starting amount of water in each jug")
+	goal := flag.String("goal", string(models.GoalAny),
+		"what measuring z means: either, x, y, sum (x + y) or exact (asks for both amounts)")
+	x := flag.Int("x", 0, "capacity of the x jug, solves without prompting along with -y and -z")
+	y := flag.Int("y", 0, "capacity of the y jug, solves without prompting along with -x and -z")
+	z := flag.String("z", "", "z goal, solves without prompting along with -x and -y.\n"+
+		"The exact goal requires both amounts separated by a comma, as in 4,0")
+	wx := flag.Int("wx", 0, "starting amount of the x jug when solving without prompting")
+	wy := flag.Int("wy", 0, "starting amount of the y jug when solving without prompting")
+	format := flag.String("format", string(app.FormatText),
+		"solution output format: text, json, csv or markdown")
+	timeout := flag.Duration("timeout", 0,
+		"stops solving a puzzle after the duration, as in 5s, exiting with status 3. No limit if not set")
+	verify := flag.Bool("verify", false, "replays every solution before printing it, failing if it is not valid")
+	pathCount := flag.Int("paths", 0,
+		"lists up to this many distinct solutions ranked by length when solving without prompting")
+	maxLength := flag.Int("max-length", 0, "maximum amount of steps of the solutions listed by -paths")
+	batchPath := flag.String("batch", "", "solves a puzzle per line from the file, or stdin if it is -")
+	batchFormat := flag.String("batch-format", string(batch.FormatCSV),
+		"batch input and output format: csv (x,y,z or x,y,z,wx,wy) or jsonl")
+	workers := flag.Int("workers", 0,
+		"number of puzzles solved at the same time in batch mode or by -table, the number of CPUs if not set")
+	costFlag := flag.String("costs", "",
+		"finds the cheapest solution given the cost of each action, as in fill=3,transfer=0.\n"+
+			"Actions are fill, empty, transfer or a single action such as fill_x, the rest cost 1")
+	dot := flag.Bool("dot", false,
+		"writes the graph of every reachable state as Graphviz DOT when solving without prompting,\n"+
+			"highlighting the solution and the states reaching z")
+	animate := flag.Bool("animate", false, "draws the jugs filling up step by step instead of listing the steps")
+	delay := flag.Duration("delay", time.Second,
+		"time between the steps drawn by -animate, waits for enter to be pressed if it is 0")
+	ascii := flag.Bool("ascii", false, "draws the jugs of -animate with ASCII characters only")
+	repl := flag.Bool("repl", false, "reads commands solving several puzzles, such as solve 5 4 3, until quit")
+	play := flag.Bool("play", false, "lets you solve the puzzle typing actions such as fill x, empty y or pour x y")
+	explain := flag.Bool("explain", false,
+		"explains whether -x, -y and -z are solvable and how many steps it takes, without solving")
+	tableMax := flag.Int("table", 0,
+		"writes the step count of every solvable z for x and y up to this capacity, solving in parallel")
+	tableFormat := flag.String("table-format", string(table.FormatCSV),
+		"format of the table written by -table or read by -lookup: csv or binary")
+	lookup := flag.String("lookup", "", "answers -x, -y and -z from the table in the file instead of solving")
+	solverName := flag.String("solver", "",
+		"solver to use, see -list-solvers. By default iterative solves -z and bfs any other goal")
+	listSolvers := flag.Bool("list-solvers", false, "lists the solvers available to -solver and exits")
+	generateCount := flag.Int("generate", 0, "writes this many random puzzles of the -difficulty as JSON")
+	difficulty := flag.String("difficulty", string(generate.Easy),
+		"difficulty of the puzzles written by -generate: easy (1-4 steps), medium (5-8) or hard (9-16)")
+	seed := flag.Int64("seed", 0, "seed of the puzzles written by -generate, a random one is used if not set")
+	unsolvable := flag.Bool("unsolvable", false, "makes -generate write puzzles without a solution")
+	maxCapacity := flag.Int("max-capacity", generate.DefaultMaxCapacity,
+		"largest capacity of the puzzles written by -generate")
 	flag.Parse()
 
+	log.SetFlags(0)
+	log.SetPrefix("wjug: ")
+
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	nonInteractive := set["x"] || set["y"] || set["z"]
+	if nonInteractive && !(set["x"] && set["y"] && set["z"]) {
+		usageError("-x, -y and -z must be set together")
+	}
+
+	if set["costs"] && (set["batch"] || set["paths"]) {
+		usageError("-costs cannot be used along with -batch or -paths")
+	}
+
+	if *listSolvers {
+		runListSolvers()
+		return
+	}
+	var registration registered
+	if set["solver"] {
+		r, ok := solvers.Lookup(*solverName)
+		if !ok {
+			usageError(fmt.Sprintf("unknown solver %q, see -list-solvers", *solverName))
+		}
+		registration = adapt(r)
+		for _, name := range []string{"costs", "paths", "generate", "table", "lookup", "explain"} {
+			if set[name] {
+				usageError("-solver cannot be used along with -" + name)
+			}
+		}
+		if registration.emptyOnly && *amounts {
+			usageError(fmt.Sprintf("-solver %s cannot be used along with -a, it only solves empty jugs", *solverName))
+		}
+	}
+
+	if set["generate"] {
+		if nonInteractive || set["batch"] || set["table"] || set["lookup"] {
+			usageError("-generate cannot be used along with -x, -y, -z, -batch, -table or -lookup")
+		}
+		if !set["seed"] {
+			*seed = time.Now().UnixNano()
+		}
+		runGenerate(generate.Configuration{
+			Count:       *generateCount,
+			Difficulty:  generate.Difficulty(*difficulty),
+			Unsolvable:  *unsolvable,
+			MaxCapacity: *maxCapacity,
+			Seed:        *seed,
+		})
+		return
+	}
+	for _, name := range []string{"difficulty", "seed", "unsolvable", "max-capacity"} {
+		if set[name] {
+			usageError("-" + name + " requires -generate")
+		}
+	}
+
+	if set["table"] {
+		if nonInteractive || set["batch"] || set["lookup"] {
+			usageError("-table cannot be used along with -x, -y, -z, -batch or -lookup")
+		}
+		runTable(*tableMax, table.Format(*tableFormat), *workers, *timeout)
+		return
+	}
+	if set["lookup"] {
+		if !nonInteractive {
+			usageError("-lookup requires -x, -y and -z")
+		}
+		runLookup(*lookup, table.Format(*tableFormat), *x, *y, *z, app.Format(*format))
+		return
+	}
+
+	if *explain {
+		if !nonInteractive {
+			usageError("-explain requires -x, -y and -z")
+		}
+		for _, name := range []string{"goal", "wx", "wy", "paths", "dot", "animate", "costs"} {
+			if set[name] {
+				usageError("-explain cannot be used along with -" + name)
+			}
+		}
+		runExplain(*x, *y, *z, app.Format(*format))
+		return
+	}
+
+	if set["batch"] {
+		if nonInteractive {
+			usageError("-batch cannot be used along with -x, -y and -z")
+		}
+		solver, goalSolver := defaultSolvers()
+		if set["solver"] {
+			solver, goalSolver = registration.solver, registration.goalSolver
+		}
+		runBatch(*batchPath, batch.Format(*batchFormat), *workers, models.GoalKind(*goal), *timeout, solver, goalSolver)
+		return
+	}
+
+	// stdin is shared by the app and -animate -delay 0, which waits for enter
+	// to be pressed, so neither buffers the lines the other one reads.
+	stdin := bufio.NewReader(os.Stdin)
+	renderer, err := app.NewRenderer(app.Format(*format))
+	if err != nil {
+		usageError(err.Error())
+	}
+	if (set["paths"] || set["max-length"]) && !nonInteractive {
+		usageError("-paths and -max-length require -x, -y and -z")
+	}
+	if *repl && (nonInteractive || *multi || set["paths"] || set["dot"]) {
+		usageError("-repl cannot be used along with -x, -y, -z, -n, -paths or -dot")
+	}
+	if *play && (nonInteractive || *multi || *repl || *silent || set["paths"] || set["dot"]) {
+		usageError("-play cannot be used along with -x, -y, -z, -n, -s, -repl, -paths or -dot")
+	}
+	if set["dot"] && !nonInteractive {
+		usageError("-dot requires -x, -y and -z")
+	}
+	if set["dot"] && (set["paths"] || set["format"]) {
+		usageError("-dot cannot be used along with -paths or -format")
+	}
+	if (set["delay"] || set["ascii"]) && !*animate {
+		usageError("-delay and -ascii require -animate")
+	}
+	if *animate {
+		if set["format"] || set["paths"] || set["dot"] {
+			usageError("-animate cannot be used along with -format, -paths or -dot")
+		}
+		renderer = animatedRenderer(*delay, *ascii, stdin)
+	}
+	if set["max-length"] && !set["paths"] {
+		usageError("-max-length requires -paths")
+	}
+
+	var multiSolver app.MultiSolver
+	if *multi {
+		multiSolver = lookupSolver("bfs").multiSolver
+		if set["solver"] {
+			multiSolver = registration.multiSolver
+		}
+		if multiSolver == nil {
+			usageError(fmt.Sprintf("solver %q cannot be used along with -n", *solverName))
+		}
+	}
+
+	solver, goalSolver := defaultSolvers()
+	if set["solver"] {
+		solver, goalSolver = registration.solver, registration.goalSolver
+	}
+	var (
+		costs    models.Costs
+		cheapest dijkstra.Solver
+	)
+	if set["costs"] {
+		costs, err = models.ParseCosts(*costFlag)
+		if err != nil {
+			usageError(err.Error())
+		}
+		cheapest = dijkstra.Solver{Costs: costs}
+		solver, goalSolver = cheapest, cheapest
+		switch r := renderer.(type) {
+		case app.TextRenderer:
+			r.Summary = true
+			renderer = r
+		case app.MarkdownRenderer:
+			r.Summary = true
+			renderer = r
+		}
+	}
+
+	replSolvers := map[string]app.GoalSolver{}
+	for _, r := range solvers.List() {
+		if r.SolveGoal != nil {
+			replSolvers[r.Name] = adapt(r).goalSolver
+		}
+	}
+	if set["costs"] {
+		replSolvers["dijkstra"] = cheapest
+	}
+
 	application, err := app.New(app.Configuration{
-		Output: os.Stdout,
-		Silent: *silent,
-		Solver: app.SolverFun(iterative.Solve),
+		Input:       stdin,
+		Output:      os.Stdout,
+		Silent:      *silent || nonInteractive,
+		Solver:      solver,
+		GoalSolver:  goalSolver,
+		Goal:        models.GoalKind(*goal),
+		Solvers:     replSolvers,
+		MultiSolver: multiSolver,
+		AskAmounts:  *amounts,
+		Renderer:    renderer,
+		Verify:      *verify,
+		Timeout:     *timeout,
+		Costs:       costs,
 	})
+	if err != nil {
+		usageError(err.Error())
+	}
+
+	if *play {
+		err = application.Play()
+		if errors.Is(err, context.DeadlineExceeded) {
+			timedOut(*timeout)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *repl {
+		err = application.REPL()
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if !nonInteractive {
+		err = application.Run()
+		if errors.Is(err, context.DeadlineExceeded) {
+			timedOut(*timeout)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	targets, err := app.ParseTargets(*z)
+	if err != nil {
+		usageError(err.Error())
+	}
+	g, err := models.NewGoal(models.GoalKind(*goal), targets...)
+	if err != nil {
+		usageError(err.Error())
+	}
+
+	state := models.State{
+		X: models.Jug{Capacity: *x, Amount: *wx},
+		Y: models.Jug{Capacity: *y, Amount: *wy},
+	}
+	if set["paths"] {
+		runPaths(state, g, *pathCount, *maxLength, app.Format(*format), renderer)
+		return
+	}
+
+	if *dot {
+		err = runDOT(application, state, g)
+	} else {
+		err = application.RunWith(state, g)
+	}
+	if errors.Is(err, app.ErrInvalidParameters) {
+		usageError(err.Error())
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		timedOut(*timeout)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
+}
+
+// animatedRenderer draws the jugs on stdout, frames are only cleared in
+// between when writing to a terminal. If delay is 0 it waits for a line from
+// input after each frame.
+func animatedRenderer(delay time.Duration, ascii bool, input *bufio.Reader) app.AnimatedRenderer {
+	r := app.AnimatedRenderer{Delay: delay, ASCII: ascii}
+	if info, err := os.Stdout.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		r.Clear = true
+	}
+	if delay == 0 {
+		r.Input = input
+	}
+	return r
+}
+
+// runGenerate writes a set of random puzzles of the configured difficulty as
+// JSON.
+func runGenerate(conf generate.Configuration) {
+	if conf.Count <= 0 {
+		usageError("-generate must be positive")
+	}
+	if _, _, ok := conf.Difficulty.Steps(); !ok {
+		usageError(fmt.Sprintf("unknown difficulty %q", conf.Difficulty))
+	}
 
-	err = application.Run()
+	set, err := generate.Generate(conf)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if err = json.NewEncoder(os.Stdout).Encode(set); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runTable writes the table of every puzzle up to max, solved by bfs so step
+// counts are the shortest.
+func runTable(max int, format table.Format, workers int, timeout time.Duration) {
+	if max <= 0 {
+		usageError("-table must be positive")
+	}
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	t, err := table.Generate(ctx, table.Configuration{
+		Max:     max,
+		Solver:  lookupSolver("bfs").solver,
+		Workers: workers,
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("timed out after %s, before the table was generated", timeout)
+		os.Exit(3)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	output := bufio.NewWriter(os.Stdout)
+	if err = t.Write(output, format); err == nil {
+		err = output.Flush()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runLookup answers the puzzle from the table in the file.
+func runLookup(path string, format table.Format, x, y int, z string, outputFormat app.Format) {
+	targets, err := app.ParseTargets(z)
+	if err != nil {
+		usageError(err.Error())
+	}
+	if len(targets) != 1 {
+		usageError("-lookup requires a single z")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+	t, err := table.Read(bufio.NewReader(file), format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	answer, err := t.Lookup(x, y, targets[0])
+	if err != nil {
+		usageError(err.Error())
+	}
+
+	switch outputFormat {
+	case app.FormatJSON:
+		err = json.NewEncoder(os.Stdout).Encode(answer)
+	case app.FormatText:
+		if answer.Solvable {
+			_, err = fmt.Printf("solvable in %d steps\n", answer.Steps)
+		} else {
+			_, err = fmt.Println("no solution")
+		}
+	default:
+		usageError("-lookup only supports the text and json formats")
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runExplain writes the analysis of the puzzle as text or JSON.
+func runExplain(x, y int, z string, format app.Format) {
+	targets, err := app.ParseTargets(z)
+	if err != nil {
+		usageError(err.Error())
+	}
+	if len(targets) != 1 {
+		usageError("-explain requires a single z")
+	}
+	analysis, err := euclid.Analyze(x, y, targets[0])
+	if err != nil {
+		usageError(err.Error())
+	}
+
+	switch format {
+	case app.FormatJSON:
+		err = json.NewEncoder(os.Stdout).Encode(analysis)
+	case app.FormatText:
+		err = writeAnalysis(os.Stdout, analysis)
+	default:
+		usageError("-explain only supports the text and json formats")
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// writeAnalysis writes the analysis as text, a line for each fact.
+func writeAnalysis(w io.Writer, a euclid.Analysis) error {
+	lines := []string{
+		fmt.Sprintf("gcd(%d, %d) = %d", a.X, a.Y, a.GCD),
+		fmt.Sprintf("%d is a multiple of %d: %t", a.Z, a.GCD, a.Multiple),
+		fmt.Sprintf("%d exceeds both capacities: %t", a.Z, a.ExceedsCapacities),
+		fmt.Sprintf("x can hold %s", a.ReachableX),
+		fmt.Sprintf("y can hold %s", a.ReachableY),
+	}
+	if a.Solvable {
+		lines = append(lines,
+			fmt.Sprintf("filling x and pouring into y takes %d steps", a.Counts.XToY),
+			fmt.Sprintf("filling y and pouring into x takes %d steps", a.Counts.YToX))
+	} else {
+		lines = append(lines, "no solution: "+a.Reason())
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// runDOT writes the state graph, highlighting the solution found by the
+// application.
+func runDOT(application app.App, state models.State, goal models.Goal) error {
+	result, err := application.Solve(state, goal)
+	if err != nil {
+		return err
+	}
+	g, err := graph.Build(state)
+	if err != nil {
+		return err
+	}
+	return g.WriteDOT(os.Stdout, goal, models.Solution{Steps: result.Steps})
+}
+
+// runPaths lists up to k solutions ranked by length, each one written as a
+// single solution would be. Text solutions are preceded by their rank.
+func runPaths(state models.State, goal models.Goal, k, maxLength int, format app.Format, renderer app.Renderer) {
+	if k <= 0 {
+		usageError("-paths must be positive")
+	}
+	it, err := paths.Enumerate(state, goal, maxLength)
+	if err != nil {
+		usageError(err.Error())
+	}
+
+	output := bufio.NewWriter(os.Stdout)
+	found := 0
+	for ; found < k && it.Next(); found++ {
+		solution := it.Solution()
+		if format == app.FormatText {
+			_, err = fmt.Fprintf(output, "#%d, %d steps\n", found+1, len(solution.Steps))
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		err = renderer.Render(output, models.NewResult(state, goal, solution, true))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if found == 0 {
+		err = renderer.Render(output, models.NewResult(state, goal, models.Solution{}, false))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err = output.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runListSolvers() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, r := range solvers.List() {
+		fmt.Fprintf(w, "%s\t%s\n", r.Name, r.Description)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runBatch solves every puzzle in the file, or stdin if path is "-".
+func runBatch(
+	path string, format batch.Format, workers int, goal models.GoalKind, timeout time.Duration,
+	solver app.Solver, goalSolver app.GoalSolver,
+) {
+	input := os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	output := bufio.NewWriter(os.Stdout)
+	err := batch.Run(batch.Configuration{
+		Input:      input,
+		Output:     output,
+		Format:     format,
+		Workers:    workers,
+		Solver:     solver,
+		GoalSolver: goalSolver,
+		Goal:       goal,
+		Timeout:    timeout,
+	})
+	if err == nil {
+		err = output.Flush()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// timedOut reports the puzzle could not be solved in time, exiting with its
+// own status code so it is not mistaken for a failure.
+func timedOut(timeout time.Duration) {
+	log.Printf("timed out after %s, before the puzzle was solved", timeout)
+	os.Exit(3)
+}
+
+// usageError reports invalid parameters, exiting with the same status code as
+// invalid flags do.
+func usageError(message string) {
+	log.Print(message)
+	os.Exit(2)
 }
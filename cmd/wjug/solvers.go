@@ -0,0 +1,52 @@
+package main
+
+// Solver packages register themselves in the solvers registry when imported,
+// importing one here makes it available to -solver, -list-solvers, compare and
+// the REPL. Every solver is looked up in the registry, even the default ones.
+import (
+	"log"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	_ "github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	_ "github.com/nacho692/live-free-or-die-jugging/pkg/dijkstra"
+	_ "github.com/nacho692/live-free-or-die-jugging/pkg/euclid"
+	_ "github.com/nacho692/live-free-or-die-jugging/pkg/iterative"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvers"
+)
+
+// registered is a registered solver adapted to the app interfaces, the
+// solvers the registration does not provide are nil.
+type registered struct {
+	name        string
+	emptyOnly   bool
+	solver      app.Solver
+	goalSolver  app.GoalSolver
+	multiSolver app.MultiSolver
+}
+
+func adapt(r solvers.Registration) registered {
+	adapted := registered{name: r.Name, emptyOnly: r.EmptyOnly, solver: app.ContextSolverFun(r.Solve)}
+	if r.SolveGoal != nil {
+		adapted.goalSolver = app.ContextGoalSolverFun(r.SolveGoal)
+	}
+	if r.SolveMulti != nil {
+		adapted.multiSolver = app.ContextMultiSolverFun(r.SolveMulti)
+	}
+	return adapted
+}
+
+// lookupSolver returns the registered solver, wjug cannot run without the solvers it
+// looks up so it exits if there is none.
+func lookupSolver(name string) registered {
+	r, ok := solvers.Lookup(name)
+	if !ok {
+		log.Fatalf("solver %q is not registered, see cmd/wjug/solvers.go", name)
+	}
+	return adapt(r)
+}
+
+// defaultSolvers returns the solvers used when -solver is not set, iterative
+// is faster but bfs supports every goal.
+func defaultSolvers() (app.Solver, app.GoalSolver) {
+	return lookupSolver("iterative").solver, lookupSolver("bfs").goalSolver
+}
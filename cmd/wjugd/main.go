@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/cache"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/iterative"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/server"
+)
+
+func main() {
+	log.SetFlags(log.LstdFlags)
+	log.SetPrefix("wjugd: ")
+
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run serves until it is interrupted, errors are returned rather than logged
+// so the cache is closed before exiting.
+func run() error {
+
+	addr := flag.String("addr", ":8080", "address to listen on")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 10*time.Second,
+		"how long in-flight requests are waited for when shutting down")
+	timeout := flag.Duration("timeout", 30*time.Second,
+		"stops solving a puzzle after the duration, answering with status 503")
+	cacheSize := flag.Int("cache", cache.DefaultSize,
+		"amount of solutions kept in memory, so the same puzzle is not solved twice. 0 disables caching")
+	cacheFile := flag.String("cache-file", "", "file where solutions are kept across restarts, along with -cache")
+	flag.Parse()
+
+	var (
+		solver     app.Solver     = app.ContextSolverFun(iterative.SolveContext)
+		goalSolver app.GoalSolver = app.ContextGoalSolverFun(bfs.SolveGoalContext)
+	)
+	if *cacheFile != "" && *cacheSize == 0 {
+		return errors.New("-cache-file requires -cache")
+	}
+	if *cacheSize > 0 {
+		conf := cache.Configuration{Solver: solver, GoalSolver: goalSolver, Size: *cacheSize, Name: "iterative,bfs"}
+		if *cacheFile != "" {
+			store, err := cache.OpenFileStore(*cacheFile)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+			conf.Store = store
+		}
+		c, err := cache.New(conf)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			stats := c.Stats()
+			log.Printf("cache hits: %d, misses: %d", stats.Hits, stats.Misses)
+		}()
+		solver, goalSolver = c, c
+	}
+
+	handler, err := server.New(server.Configuration{
+		Solver:     solver,
+		GoalSolver: goalSolver,
+		Timeout:    *timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %s", *addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err = <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Print("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	err = srv.Shutdown(shutdownCtx)
+	if err != nil {
+		return err
+	}
+	if err = <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// AnimatedRenderer draws the jugs as bars filled up to their amount, a frame
+// for the initial state and another one for each step, titled by the action
+// taken. Jugs touched by the action are labelled in brackets, as in [X].
+//
+// Frames are written one after the other, waiting Delay in between, unless
+// Input is set. Unsolvable puzzles are written as "no solution".
+type AnimatedRenderer struct {
+	// Delay is the time waited after writing each frame.
+	Delay time.Duration
+	// Input is optional, if set, a line is read from it after writing each
+	// frame instead of waiting Delay, so pressing enter shows the next one.
+	// A *bufio.Reader is read directly, so it can be shared with the
+	// Configuration.Input of an App without either losing lines.
+	Input io.Reader
+	// Clear clears the terminal before writing each frame, it should only be
+	// set when writing to a terminal.
+	Clear bool
+	// ASCII draws the jugs with ASCII characters instead of Unicode ones.
+	ASCII bool
+	// Height is the amount of rows of the largest jug, 8 if it is zero.
+	Height int
+}
+
+func (r AnimatedRenderer) Render(w io.Writer, result models.Result) error {
+	if !result.Solvable {
+		_, err := fmt.Fprintln(w, noSolution)
+		return err
+	}
+
+	names := []string{"X", "Y"}
+	frames := []frame{{title: "Start", jugs: result.Initial.Multi().Jugs}}
+	for _, step := range result.Steps {
+		frames = append(frames, frame{
+			title:   string(step.Action),
+			jugs:    step.State.Multi().Jugs,
+			touched: touchedBy[step.Action],
+		})
+	}
+	return r.play(w, names, frames)
+}
+
+func (r AnimatedRenderer) RenderMulti(w io.Writer, result models.MultiResult) error {
+	if !result.Solvable {
+		_, err := fmt.Fprintln(w, noSolution)
+		return err
+	}
+
+	var names []string
+	for i := range result.Initial.Jugs {
+		names = append(names, fmt.Sprint(i+1))
+	}
+	frames := []frame{{title: "Start", jugs: result.Initial.Jugs}}
+	for _, step := range result.Steps {
+		touched := []int{step.Move.From}
+		if step.Move.Kind == models.MovePour {
+			touched = append(touched, step.Move.To)
+		}
+		frames = append(frames, frame{
+			title:   step.Move.String(),
+			jugs:    step.State.Jugs,
+			touched: touched,
+		})
+	}
+	return r.play(w, names, frames)
+}
+
+// touchedBy indexes the jugs each action changes, X being 0 and Y being 1.
+var touchedBy = map[models.Action][]int{
+	models.ActionFillX:     {0},
+	models.ActionFillY:     {1},
+	models.ActionEmptyX:    {0},
+	models.ActionEmptyY:    {1},
+	models.ActionTransferX: {1, 0},
+	models.ActionTransferY: {0, 1},
+}
+
+// frame is a single picture of the jugs.
+type frame struct {
+	title   string
+	jugs    []models.Jug
+	touched []int
+}
+
+// play writes every frame, waiting in between.
+func (r AnimatedRenderer) play(w io.Writer, names []string, frames []frame) error {
+	var input *bufio.Reader
+	if r.Input != nil {
+		input = bufio.NewReader(r.Input)
+	}
+
+	for i, f := range frames {
+		if r.Clear {
+			if _, err := io.WriteString(w, "\033[H\033[2J"); err != nil {
+				return err
+			}
+		}
+		if err := r.draw(w, fmt.Sprintf("Step %d/%d: %s", i, len(frames)-1, f.title), names, f); err != nil {
+			return err
+		}
+		if i == len(frames)-1 {
+			break
+		}
+
+		if input == nil {
+			time.Sleep(r.Delay)
+			continue
+		}
+		// Once the input is over the remaining frames are written right
+		// away.
+		if _, err := input.ReadString('\n'); err == io.EOF {
+			input = nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// draw writes a single frame, jugs are drawn side by side and as tall as their
+// capacity relative to the largest one.
+func (r AnimatedRenderer) draw(w io.Writer, title string, names []string, f frame) error {
+	side, fill, bottom := "│", "█", "─"
+	corners := [2]string{"└", "┘"}
+	if r.ASCII {
+		side, fill, bottom = "|", "#", "-"
+		corners = [2]string{"+", "+"}
+	}
+	height := r.Height
+	if height <= 0 {
+		height = 8
+	}
+
+	largest := 0
+	for _, jug := range f.jugs {
+		if jug.Capacity > largest {
+			largest = jug.Capacity
+		}
+	}
+
+	touched := map[int]bool{}
+	for _, i := range f.touched {
+		touched[i] = true
+	}
+
+	var (
+		rows    = make([]int, len(f.jugs))
+		filled  = make([]int, len(f.jugs))
+		widths  = make([]int, len(f.jugs))
+		labels  = make([]string, len(f.jugs))
+		amounts = make([]string, len(f.jugs))
+	)
+	for i, jug := range f.jugs {
+		rows[i], filled[i] = level(jug, largest, height)
+		amounts[i] = fmt.Sprintf("%d/%d", jug.Amount, jug.Capacity)
+		labels[i] = names[i]
+		if touched[i] {
+			labels[i] = "[" + names[i] + "]"
+		}
+		// Wide enough for any amount, so the jug keeps its width.
+		widths[i] = 2*len(fmt.Sprint(jug.Capacity)) + 1
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", title)
+	for row := height; row >= 1; row-- {
+		for i := range f.jugs {
+			switch {
+			case row > rows[i]:
+				b.WriteString(strings.Repeat(" ", widths[i]+2))
+			case row <= filled[i]:
+				b.WriteString(side + strings.Repeat(fill, widths[i]) + side)
+			default:
+				b.WriteString(side + strings.Repeat(" ", widths[i]) + side)
+			}
+			b.WriteString("  ")
+		}
+		b.WriteString("\n")
+	}
+	for i := range f.jugs {
+		b.WriteString(corners[0] + strings.Repeat(bottom, widths[i]) + corners[1] + "  ")
+	}
+	b.WriteString("\n")
+	for _, line := range [][]string{labels, amounts} {
+		for i := range f.jugs {
+			b.WriteString(center(line[i], widths[i]+2) + "  ")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	// Trailing spaces are left out, as empty rows are only spaces.
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// level returns how many rows the jug takes and how many of them are filled,
+// so that a jug holding some water never looks empty and a jug which is not
+// full never looks full.
+func level(jug models.Jug, largest, height int) (rows, filled int) {
+	rows = (jug.Capacity*height + largest/2) / largest
+	if rows < 1 {
+		rows = 1
+	}
+	filled = (jug.Amount*rows + jug.Capacity/2) / jug.Capacity
+	if filled == 0 && jug.Amount > 0 {
+		filled = 1
+	}
+	if filled == rows && jug.Amount < jug.Capacity && rows > 1 {
+		filled = rows - 1
+	}
+	return rows, filled
+}
+
+// center pads s with spaces on both sides up to width.
+func center(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+	left := (width - len(s)) / 2
+	return strings.Repeat(" ", left) + s + strings.Repeat(" ", width-len(s)-left)
+}
@@ -0,0 +1,131 @@
+package app_test
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+func TestAnimatedRenderer(t *testing.T) {
+
+	initial := models.State{
+		X: models.Jug{Capacity: 4},
+		Y: models.Jug{Capacity: 2},
+	}
+	solved := models.NewResult(initial, models.AnyJug{Z: 2}, models.Solution{
+		Steps: []models.Step{
+			{
+				State: models.State{
+					X: models.Jug{Capacity: 4, Amount: 4},
+					Y: models.Jug{Capacity: 2, Amount: 0},
+				},
+				Action: models.ActionFillX,
+			},
+			{
+				State: models.State{
+					X: models.Jug{Capacity: 4, Amount: 2},
+					Y: models.Jug{Capacity: 2, Amount: 2},
+				},
+				Action: models.ActionTransferY,
+			},
+		},
+	}, true)
+
+	t.Run("a frame for each step", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		require.NoError(t, app.AnimatedRenderer{ASCII: true, Height: 4}.Render(output, solved))
+		assert.Equal(t, "Step 0/2: Start\n\n"+
+			"|   |\n"+
+			"|   |\n"+
+			"|   |  |   |\n"+
+			"|   |  |   |\n"+
+			"+---+  +---+\n"+
+			"  X      Y\n"+
+			" 0/4    0/2\n\n"+
+			"Step 1/2: Fill X\n\n"+
+			"|###|\n"+
+			"|###|\n"+
+			"|###|  |   |\n"+
+			"|###|  |   |\n"+
+			"+---+  +---+\n"+
+			" [X]     Y\n"+
+			" 4/4    0/2\n\n"+
+			"Step 2/2: Transfer to Y\n\n"+
+			"|   |\n"+
+			"|   |\n"+
+			"|###|  |###|\n"+
+			"|###|  |###|\n"+
+			"+---+  +---+\n"+
+			" [X]    [Y]\n"+
+			" 2/4    2/2\n\n", output.String())
+	})
+
+	t.Run("some water never looks empty nor full", func(t *testing.T) {
+		result := models.NewResult(models.State{
+			X: models.Jug{Capacity: 100, Amount: 1},
+			Y: models.Jug{Capacity: 100, Amount: 99},
+		}, models.AnyJug{Z: 1}, models.Solution{}, true)
+
+		output := &bytes.Buffer{}
+		require.NoError(t, app.AnimatedRenderer{ASCII: true, Height: 2}.Render(output, result))
+		assert.Contains(t, output.String(), "|       |  |       |\n|#######|  |#######|\n")
+	})
+
+	t.Run("frames wait for the input", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		renderer := app.AnimatedRenderer{Input: strings.NewReader("\n"), Clear: true}
+		require.NoError(t, renderer.Render(output, solved), "the input ending does not stop the frames")
+		assert.Equal(t, 3, strings.Count(output.String(), "\033[H\033[2J"))
+		assert.Contains(t, output.String(), "└───┘  └───┘")
+	})
+
+	t.Run("shared input keeps the following lines", func(t *testing.T) {
+		input := bufio.NewReader(strings.NewReader("\n\nquit\n"))
+		renderer := app.AnimatedRenderer{Input: input}
+		require.NoError(t, renderer.Render(&bytes.Buffer{}, solved))
+
+		line, err := input.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "quit\n", line)
+	})
+
+	t.Run("more than two jugs", func(t *testing.T) {
+		result := models.NewMultiResult(models.MultiState{Jugs: []models.Jug{
+			{Capacity: 1}, {Capacity: 2}, {Capacity: 3},
+		}}, models.AnyJug{Z: 1}, models.MultiSolution{
+			Steps: []models.MultiStep{
+				{
+					State: models.MultiState{Jugs: []models.Jug{
+						{Capacity: 1}, {Capacity: 2}, {Capacity: 3, Amount: 3},
+					}},
+					Move: models.Fill(2),
+				},
+				{
+					State: models.MultiState{Jugs: []models.Jug{
+						{Capacity: 1, Amount: 1}, {Capacity: 2}, {Capacity: 3, Amount: 2},
+					}},
+					Move: models.Pour(2, 0),
+				},
+			},
+		}, true)
+
+		output := &bytes.Buffer{}
+		require.NoError(t, app.AnimatedRenderer{}.RenderMulti(output, result))
+		assert.Contains(t, output.String(), "Step 2/2: Transfer jug 3 to jug 1\n")
+		assert.Contains(t, output.String(), " [1]     2     [3]\n")
+	})
+
+	t.Run("no solution", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		unsolvable := models.NewResult(initial, models.AnyJug{Z: 3}, models.Solution{}, false)
+		require.NoError(t, app.AnimatedRenderer{}.Render(output, unsolvable))
+		assert.Equal(t, "no solution\n", output.String())
+	})
+}
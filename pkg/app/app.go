@@ -4,15 +4,21 @@ package app
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
 )
 
+// ErrInvalidParameters indicates that the puzzle parameters are not valid, as
+// in z exceeding both jugs.
+var ErrInvalidParameters = errors.New("invalid parameters")
+
 // SolverFun is a wrapper to simplify the solver interface implementation.
 // go does not allow unnamed function types to implement interfaces.
 type SolverFun func(state models.State, z int) (models.Solution, error)
@@ -31,6 +37,41 @@ type Solver interface {
 	Solve(state models.State, z int) (models.Solution, error)
 }
 
+// GoalSolverFun is a wrapper to simplify the GoalSolver interface
+// implementation.
+type GoalSolverFun func(state models.State, goal models.Goal) (models.Solution, error)
+
+// SolveGoal just wraps the internal solver solve.
+func (s GoalSolverFun) SolveGoal(state models.State, goal models.Goal) (models.Solution, error) {
+	return s(state, goal)
+}
+
+// GoalSolver must implement a solution to the water jug riddle for any
+// models.Goal, not only measuring z in either jug.
+//
+// The same constraints as Solver apply, goals the solver cannot handle must
+// return models.ErrUnsupported.
+type GoalSolver interface {
+	SolveGoal(state models.State, goal models.Goal) (models.Solution, error)
+}
+
+// MultiSolverFun is a wrapper to simplify the MultiSolver interface
+// implementation.
+type MultiSolverFun func(state models.MultiState, z int) (models.MultiSolution, error)
+
+// SolveMulti just wraps the internal solver solve.
+func (s MultiSolverFun) SolveMulti(state models.MultiState, z int) (models.MultiSolution, error) {
+	return s(state, z)
+}
+
+// MultiSolver must implement a solution to the water jug riddle for any number
+// of jugs, z must be measured in any of them.
+//
+// The same constraints as Solver apply.
+type MultiSolver interface {
+	SolveMulti(state models.MultiState, z int) (models.MultiSolution, error)
+}
+
 // Configuration is the base configuration for instantiating an interactive App.
 type Configuration struct {
 	// Output allows configuration for the app output, if nil, stdout is used
@@ -45,7 +86,41 @@ type Configuration struct {
 	// As it will only output the solution.
 	Silent bool
 	// Solver must be a valid solver, see Solver for more information.
+	// It solves the models.GoalAny goal, it is optional if GoalSolver is set.
 	Solver Solver
+	// GoalSolver solves every other goal, it is required if Goal is not
+	// models.GoalAny. See GoalSolver for more information.
+	GoalSolver GoalSolver
+	// Goal is the kind of goal the user is asked for, models.GoalAny is used
+	// by default.
+	Goal models.GoalKind
+	// Solvers is optional, it names the solvers which can be chosen with the
+	// "set solver" command of the REPL. The chosen one solves every goal.
+	Solvers map[string]GoalSolver
+	// MultiSolver is optional, if set, the user is asked for the number of
+	// jugs first. Puzzles with exactly two jugs are still solved by Solver.
+	MultiSolver MultiSolver
+	// Renderer configures how the solution is written, TextRenderer is used
+	// by default. See Renderer for more information.
+	Renderer Renderer
+	// AskAmounts configures whether the user is asked for the starting amount
+	// of water in each jug, otherwise they start empty.
+	AskAmounts bool
+	// Verify replays every solution before writing it, so a faulty solver
+	// results in an error wrapping models.ErrInvalidSolution instead of a
+	// wrong solution. See models.Validate.
+	Verify bool
+	// Timeout bounds how long solving each puzzle may take, there is no
+	// limit if it is zero. Once it is exceeded the run ends with an error
+	// wrapping context.DeadlineExceeded.
+	//
+	// Solvers are only stopped while searching if they are a ContextSolver,
+	// ContextGoalSolver or ContextMultiSolver, see WithContext.
+	Timeout time.Duration
+	// Costs weighs the actions of every solution, the Cost of a Result is
+	// its amount of steps if it is nil. It does not change how solutions are
+	// found, see the dijkstra package for the cheapest ones.
+	Costs models.Costs
 }
 
 // App is an interactive application which guides the user through the water
@@ -57,6 +132,15 @@ type App struct {
 	input          reader
 	solutionOutput writer
 	solver         Solver
+	goalSolver     GoalSolver
+	goal           models.GoalKind
+	solvers        map[string]GoalSolver
+	multiSolver    MultiSolver
+	askAmounts     bool
+	renderer       Renderer
+	verify         bool
+	timeout        time.Duration
+	costs          models.Costs
 }
 
 // New instantiates a new App.
@@ -81,15 +165,46 @@ func New(conf Configuration) (App, error) {
 		conf.Input = os.Stdin
 	}
 
-	if conf.Solver == nil {
+	if conf.Solver == nil && conf.GoalSolver == nil {
 		return App{}, errors.New("solver cannot be nil")
 	}
+	if err := conf.Costs.Validate(); err != nil {
+		return App{}, err
+	}
+
+	goal := conf.Goal
+	if goal == "" {
+		goal = models.GoalAny
+	}
+	if _, err := models.NewGoal(goal, 0); err != nil {
+		return App{}, err
+	}
+	if goal != models.GoalAny && conf.GoalSolver == nil {
+		return App{}, fmt.Errorf("goal solver cannot be nil for goal %s", goal)
+	}
+
+	renderer := conf.Renderer
+	if renderer == nil {
+		renderer = TextRenderer{}
+	}
+	if _, ok := renderer.(MultiRenderer); conf.MultiSolver != nil && !ok {
+		return App{}, errors.New("renderer must be a MultiRenderer to use a MultiSolver")
+	}
 
 	return App{
 		input:          reader{bufio.NewReader(conf.Input)},
 		output:         writer{output},
 		solutionOutput: writer{solutionOutput},
 		solver:         conf.Solver,
+		goalSolver:     conf.GoalSolver,
+		goal:           goal,
+		solvers:        conf.Solvers,
+		multiSolver:    conf.MultiSolver,
+		askAmounts:     conf.AskAmounts,
+		renderer:       renderer,
+		verify:         conf.Verify,
+		timeout:        conf.Timeout,
+		costs:          conf.Costs,
 	}, nil
 }
 
@@ -119,74 +234,366 @@ func New(conf Configuration) (App, error) {
 // (5/5, 3/4)
 //
 // If no solution exists, "no solution" is written to the output.
+//
+// This is the output of the default TextRenderer, other renderers write the
+// solution in their own format, see Renderer.
+//
+// Other goal kinds request z the same way, but for models.GoalExact which
+// requests the goal amount for each jug instead, as in "x\ny\nw_x\nw_y\n".
+//
+// If the App asks for amounts, the starting amount of each jug is requested
+// after the capacities, so the input would look like "x\ny\nw_x\nw_y\nz\n".
+//
+// If the App has a MultiSolver, the number of jugs is requested first, so the
+// input would look like "n\nc_1\n...c_n\nz\n". Two jugs are requested as x
+// and y, any other number of jugs is written as:
+// ACTION  (Fill/Transfer/Empty; see models.Move)
+// (w_1/c_1, ..., w_n/c_n) (current amount of water over max capacity for each jug)
 func (a *App) Run() error {
+	return a.RunContext(context.Background())
+}
+
+// RunContext is Run, but solving stops with an error wrapping ctx.Err() once
+// ctx is done. Waiting for the input is not stopped.
+func (a *App) RunContext(ctx context.Context) error {
 
 	err := a.output.Write(welcome)
 	if err != nil {
 		return err
 	}
 
-	var x, y, z int
+	if a.multiSolver != nil {
+		n, err := a.requestPositiveNumber(requestJugs)
+		if err != nil {
+			return fmt.Errorf("requesting positive number: %w", err)
+		}
+		if n != 2 && a.goal != models.GoalAny {
+			return fmt.Errorf("goal %s requires two jugs", a.goal)
+		}
+		if n != 2 {
+			return a.runMulti(ctx, n)
+		}
+	}
+
+	state, goal, err := a.requestPuzzle()
+	if err != nil {
+		return err
+	}
+	return a.solveAndWrite(ctx, state, goal)
+}
+
+// requestPuzzle requests the capacities, the starting amounts if the App asks
+// for them and the goal, until they are valid.
+func (a *App) requestPuzzle() (models.State, models.Goal, error) {
+
+	var (
+		x, y  int
+		state models.State
+		goal  models.Goal
+		err   error
+	)
 	for {
 		x, err = a.requestPositiveNumber(requestX)
 		if err != nil {
-			return fmt.Errorf("requesting positive number: %w", err)
+			return models.State{}, nil, fmt.Errorf("requesting positive number: %w", err)
 		}
 
 		y, err = a.requestPositiveNumber(requestY)
 		if err != nil {
-			return fmt.Errorf("requesting positive number: %w", err)
+			return models.State{}, nil, fmt.Errorf("requesting positive number: %w", err)
 		}
 
-		z, err = a.requestNonNegativeNumber(requestZ)
-		valid, err := a.validateParameters(x, y, z)
+		state = models.State{
+			X: models.Jug{
+				Capacity: x,
+			},
+			Y: models.Jug{
+				Capacity: y,
+			},
+		}
+		if a.askAmounts {
+			state.X.Amount, err = a.requestAmount(fmt.Sprintf(requestAmount, `the "x" jug`), x)
+			if err != nil {
+				return models.State{}, nil, fmt.Errorf("requesting amount: %w", err)
+			}
+			state.Y.Amount, err = a.requestAmount(fmt.Sprintf(requestAmount, `the "y" jug`), y)
+			if err != nil {
+				return models.State{}, nil, fmt.Errorf("requesting amount: %w", err)
+			}
+		}
+
+		goal, err = a.requestGoal(state)
 		if err != nil {
-			return fmt.Errorf("validating parameters: %w", err)
+			return models.State{}, nil, fmt.Errorf("requesting goal: %w", err)
+		}
+		valid, err := a.validateParameters(x, y, goal)
+		if err != nil {
+			return models.State{}, nil, fmt.Errorf("validating parameters: %w", err)
 		}
 		if valid {
 			break
 		}
 	}
 
-	s, err := a.solver.Solve(models.State{
-		X: models.Jug{
-			Capacity: x,
-		},
-		Y: models.Jug{
-			Capacity: y,
-		},
-	}, z)
+	return state, goal, nil
+}
+
+// RunWith is the non-interactive counterpart of Run, it solves the puzzle
+// for the given parameters and writes the solution the same way Run does.
+//
+// Parameters are checked the same way Run does, an error wrapping
+// ErrInvalidParameters is returned if they are not valid.
+func (a *App) RunWith(state models.State, goal models.Goal) error {
+	return a.RunWithContext(context.Background(), state, goal)
+}
+
+// RunWithContext is RunWith, but solving stops with an error wrapping
+// ctx.Err() once ctx is done.
+func (a *App) RunWithContext(ctx context.Context, state models.State, goal models.Goal) error {
+
+	result, err := a.SolveContext(ctx, state, goal)
+	if err != nil {
+		return err
+	}
+	return a.write(result)
+}
+
+// Solve checks the parameters the same way RunWith does and solves the puzzle,
+// without writing the solution.
+//
+// Puzzles without a solution are not an error, the Result is not solvable
+// instead. Solve is safe for concurrent use as long as the solvers are.
+func (a *App) Solve(state models.State, goal models.Goal) (models.Result, error) {
+	return a.SolveContext(context.Background(), state, goal)
+}
+
+// SolveContext is Solve, but solving stops with an error wrapping ctx.Err()
+// once ctx is done.
+func (a *App) SolveContext(ctx context.Context, state models.State, goal models.Goal) (models.Result, error) {
 
-	if err != nil && errors.Is(err, models.ErrNoSolution) {
-		return a.solutionOutput.WriteLn(noSolution)
+	message := invalidParameters(state.X.Capacity, state.Y.Capacity, goal)
+	if message != "" {
+		return models.Result{}, fmt.Errorf("%w: %s", ErrInvalidParameters, message)
 	}
+	err := state.CheckAmounts()
 	if err != nil {
-		return fmt.Errorf("finding solution: %w", err)
+		return models.Result{}, fmt.Errorf("%w: %s", ErrInvalidParameters, err)
+	}
+	if _, ok := goal.(models.AnyJug); !ok && a.goalSolver == nil {
+		return models.Result{}, fmt.Errorf("%w: goal %s is not supported", ErrInvalidParameters, goal)
 	}
 
-	for _, step := range s.Steps {
-		err = a.solutionOutput.Write(
-			fmt.Sprintf("%s \n(%d/%d, %d/%d) \n",
-				step.Action,
-				step.State.X.Amount, step.State.X.Capacity,
-				step.State.Y.Amount, step.State.Y.Capacity))
+	return a.result(ctx, state, goal)
+}
+
+// solveAndWrite solves the puzzle and writes the solution to the App output.
+func (a *App) solveAndWrite(ctx context.Context, state models.State, goal models.Goal) error {
+
+	result, err := a.result(ctx, state, goal)
+	if err != nil {
+		return err
+	}
+	return a.write(result)
+}
+
+// result solves the puzzle, which must be valid.
+func (a *App) result(ctx context.Context, state models.State, goal models.Goal) (models.Result, error) {
+
+	s, err := a.solve(ctx, state, goal)
+
+	solvable := !errors.Is(err, models.ErrNoSolution)
+	if err != nil && solvable {
+		return models.Result{}, fmt.Errorf("finding solution: %w", err)
+	}
+	if solvable && a.verify {
+		err = models.Validate(state, goal, s)
 		if err != nil {
-			return fmt.Errorf("writing solution to output: %w", err)
+			return models.Result{}, fmt.Errorf("verifying solution: %w", err)
 		}
 	}
+	result := models.NewResult(state, goal, s, solvable)
+	if a.costs != nil {
+		result.Weigh(a.costs)
+	}
+	return result, nil
+}
+
+func (a *App) write(result models.Result) error {
+	err := a.renderer.Render(a.solutionOutput.output, result)
+	if err != nil {
+		return fmt.Errorf("writing solution to output: %w", err)
+	}
+	return nil
+}
+
+// runMulti requests the capacities of n jugs and the z goal, then writes the
+// solution to the App output.
+func (a *App) runMulti(ctx context.Context, n int) error {
+
+	var (
+		state models.MultiState
+		z     int
+		err   error
+	)
+	for {
+		state = models.MultiState{Jugs: make([]models.Jug, n)}
+		for i := range state.Jugs {
+			state.Jugs[i].Capacity, err = a.requestPositiveNumber(fmt.Sprintf(requestJug, i+1))
+			if err != nil {
+				return fmt.Errorf("requesting positive number: %w", err)
+			}
+		}
+		if a.askAmounts {
+			for i, jug := range state.Jugs {
+				state.Jugs[i].Amount, err = a.requestAmount(
+					fmt.Sprintf(requestAmount, fmt.Sprintf("jug %d", i+1)), jug.Capacity)
+				if err != nil {
+					return fmt.Errorf("requesting amount: %w", err)
+				}
+			}
+		}
+
+		z, err = a.requestNonNegativeNumber(requestMultiZ)
+		if err != nil {
+			return fmt.Errorf("requesting non negative number: %w", err)
+		}
+		valid, err := a.validateMultiParameters(state, z)
+		if err != nil {
+			return fmt.Errorf("validating parameters: %w", err)
+		}
+		if valid {
+			break
+		}
+	}
+
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+	s, err := MultiWithContext(a.multiSolver).SolveMultiContext(ctx, state, z)
+
+	solvable := !errors.Is(err, models.ErrNoSolution)
+	if err != nil && solvable {
+		return fmt.Errorf("finding solution: %w", err)
+	}
+	if solvable && a.verify {
+		err = models.ValidateMulti(state, models.AnyJug{Z: z}, s)
+		if err != nil {
+			return fmt.Errorf("verifying solution: %w", err)
+		}
+	}
+
+	err = a.renderer.(MultiRenderer).RenderMulti(a.solutionOutput.output,
+		models.NewMultiResult(state, models.AnyJug{Z: z}, s, solvable))
+	if err != nil {
+		return fmt.Errorf("writing solution to output: %w", err)
+	}
 	return nil
 }
 
-func (a *App) validateParameters(x, y, z int) (bool, error) {
+func (a *App) validateMultiParameters(state models.MultiState, z int) (bool, error) {
+	for _, jug := range state.Jugs {
+		if z <= jug.Capacity {
+			return true, nil
+		}
+	}
+	return false, a.output.WriteLn(zSmallerMulti)
+}
+
+// solve uses the Solver for the original riddle if there is one, the
+// GoalSolver otherwise.
+//
+// Solving is stopped once ctx is done or the App timeout is exceeded.
+func (a *App) solve(ctx context.Context, state models.State, goal models.Goal) (models.Solution, error) {
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	if g, ok := goal.(models.AnyJug); ok && a.solver != nil {
+		return WithContext(a.solver).SolveContext(ctx, state, g.Z)
+	}
+	return GoalWithContext(a.goalSolver).SolveGoalContext(ctx, state, goal)
+}
+
+// withTimeout bounds the context by the App timeout, if there is one.
+func (a *App) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if a.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, a.timeout)
+}
+
+// requestGoal requests the targets of the App goal kind, every goal requires
+// a single z but models.GoalExact, which requires an amount for each jug.
+func (a *App) requestGoal(state models.State) (models.Goal, error) {
+
+	if a.goal == models.GoalExact {
+		wx, err := a.requestAmount(fmt.Sprintf(requestGoalAmount, `the "x" jug`), state.X.Capacity)
+		if err != nil {
+			return nil, err
+		}
+		wy, err := a.requestAmount(fmt.Sprintf(requestGoalAmount, `the "y" jug`), state.Y.Capacity)
+		if err != nil {
+			return nil, err
+		}
+		return models.NewGoal(a.goal, wx, wy)
+	}
+
+	message := requestZ
+	switch a.goal {
+	case models.GoalX:
+		message = fmt.Sprintf(requestZJug, "x")
+	case models.GoalY:
+		message = fmt.Sprintf(requestZJug, "y")
+	case models.GoalSum:
+		message = requestZSum
+	}
+	z, err := a.requestNonNegativeNumber(message)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewGoal(a.goal, z)
+}
+
+func (a *App) validateParameters(x, y int, goal models.Goal) (bool, error) {
+	message := invalidParameters(x, y, goal)
+	if message != "" {
+		return false, a.output.WriteLn(message)
+	}
+	return true, nil
+}
+
+// invalidParameters returns the message for the user if the parameters are
+// not valid, an empty string otherwise.
+func invalidParameters(x, y int, goal models.Goal) string {
+	switch g := goal.(type) {
+	case models.AnyJug:
+		if g.Z > x && g.Z > y {
+			return zSmaller
+		}
+	case models.InJug:
+		if g.Jug == models.JugX && g.Z > x {
+			return fmt.Sprintf(zSmallerJug, "x")
+		}
+		if g.Jug == models.JugY && g.Z > y {
+			return fmt.Sprintf(zSmallerJug, "y")
+		}
+	case models.Sum:
+		if g.Z > x+y {
+			return zSmallerSum
+		}
+	}
+
 	switch {
-	case z > x && z > y:
-		return false, a.output.WriteLn(zSmaller)
-	case z < 0:
-		return false, a.output.WriteLn(zNegative)
 	case y <= 0 || x <= 0:
-		return false, a.output.WriteLn(xyNotPositive)
+		return xyNotPositive
 	}
-	return true, nil
+
+	err := goal.Validate(models.State{
+		X: models.Jug{Capacity: x},
+		Y: models.Jug{Capacity: y},
+	}.Jugs())
+	if err != nil {
+		return err.Error()
+	}
+	return ""
 }
 
 func (a *App) requestPositiveNumber(message string) (int, error) {
@@ -238,3 +645,22 @@ func (a *App) requestNonNegativeNumber(message string) (int, error) {
 		return number, nil
 	}
 }
+
+// requestAmount requests an amount of water between 0 and the capacity.
+func (a *App) requestAmount(message string, capacity int) (int, error) {
+
+	for {
+		number, err := a.requestNonNegativeNumber(message)
+		if err != nil {
+			return 0, err
+		}
+		if number > capacity {
+			err = a.output.WriteLn(amountTooBig)
+			if err != nil {
+				return 0, err
+			}
+			continue
+		}
+		return number, nil
+	}
+}
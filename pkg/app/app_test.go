@@ -15,6 +15,11 @@ import (
 // do not make sense to test.
 func TestRun(t *testing.T) {
 
+	unexpected := func(state models.State, z int) (models.Solution, error) {
+		t.Error("unexpected call to the two jugs solver")
+		return models.Solution{}, nil
+	}
+
 	t.Run("happy path with x=3, y=2, z=1", func(t *testing.T) {
 
 		expected := func(state models.State, z int) (models.Solution, error) {
@@ -87,4 +92,487 @@ func TestRun(t *testing.T) {
 			"no solution\n")
 	})
 
+	t.Run("starting amounts x=2/3, y=0/2, z=1", func(t *testing.T) {
+
+		expected := func(state models.State, z int) (models.Solution, error) {
+			assert.Equal(t, models.State{
+				X: models.Jug{Capacity: 3, Amount: 2},
+				Y: models.Jug{Capacity: 2},
+			}, state)
+			assert.Equal(t, 1, z)
+			return models.Solution{
+				Steps: []models.Step{
+					{
+						State: models.State{
+							X: models.Jug{Capacity: 3, Amount: 0},
+							Y: models.Jug{Capacity: 2, Amount: 2},
+						},
+						Action: models.ActionTransferY,
+					},
+				},
+			}, nil
+		}
+		// The amount 4 is re-requested as it exceeds the capacity.
+		input := "3\n2\n4\n2\n0\n1\n"
+		output := &bytes.Buffer{}
+		a, err := app.New(app.Configuration{
+			Input:      bytes.NewReader([]byte(input)),
+			Output:     output,
+			Silent:     true,
+			Solver:     app.SolverFun(expected),
+			AskAmounts: true,
+		})
+		require.NoError(t, err)
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assert.Equal(t, output.String(),
+			"Transfer to Y \n(0/3, 2/2) \n")
+	})
+
+	t.Run("sum goal allows z up to x + y", func(t *testing.T) {
+
+		expected := func(state models.State, goal models.Goal) (models.Solution, error) {
+			assert.Equal(t, models.State{
+				X: models.Jug{Capacity: 3},
+				Y: models.Jug{Capacity: 2},
+			}, state)
+			assert.Equal(t, models.Sum{Z: 5}, goal)
+			return models.Solution{
+				Steps: []models.Step{
+					{
+						State: models.State{
+							X: models.Jug{Capacity: 3, Amount: 3},
+							Y: models.Jug{Capacity: 2, Amount: 0},
+						},
+						Action: models.ActionFillX,
+					},
+					{
+						State: models.State{
+							X: models.Jug{Capacity: 3, Amount: 3},
+							Y: models.Jug{Capacity: 2, Amount: 2},
+						},
+						Action: models.ActionFillY,
+					},
+				},
+			}, nil
+		}
+		// z = 6 is re-requested as it exceeds x + y.
+		input := "3\n2\n6\n3\n2\n5\n"
+		output := &bytes.Buffer{}
+		a, err := app.New(app.Configuration{
+			Input:      bytes.NewReader([]byte(input)),
+			Output:     output,
+			Silent:     true,
+			Solver:     app.SolverFun(unexpected),
+			GoalSolver: app.GoalSolverFun(expected),
+			Goal:       models.GoalSum,
+		})
+		require.NoError(t, err)
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assert.Equal(t, output.String(),
+			"Fill X \n(3/3, 0/2) \n"+
+				"Fill Y \n(3/3, 2/2) \n")
+	})
+
+	t.Run("exact goal requests both amounts", func(t *testing.T) {
+
+		expected := func(state models.State, goal models.Goal) (models.Solution, error) {
+			assert.Equal(t, models.Exact{Amounts: []int{1, 0}}, goal)
+			return models.Solution{}, models.ErrNoSolution
+		}
+		input := "3\n2\n1\n0\n"
+		output := &bytes.Buffer{}
+		a, err := app.New(app.Configuration{
+			Input:      bytes.NewReader([]byte(input)),
+			Output:     output,
+			Silent:     true,
+			GoalSolver: app.GoalSolverFun(expected),
+			Goal:       models.GoalExact,
+		})
+		require.NoError(t, err)
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assert.Equal(t, output.String(),
+			"no solution\n")
+	})
+
+	t.Run("json format x=3, y=2, z=1", func(t *testing.T) {
+
+		expected := func(state models.State, z int) (models.Solution, error) {
+			return models.Solution{
+				Steps: []models.Step{
+					{
+						State: models.State{
+							X: models.Jug{Capacity: 3, Amount: 3},
+							Y: models.Jug{Capacity: 2, Amount: 0},
+						},
+						Action: models.ActionFillX,
+					},
+				},
+			}, nil
+		}
+		input := "3\n2\n3\n"
+		output := &bytes.Buffer{}
+		a, err := app.New(app.Configuration{
+			Input:    bytes.NewReader([]byte(input)),
+			Output:   output,
+			Silent:   true,
+			Solver:   app.SolverFun(expected),
+			Renderer: app.JSONRenderer{},
+		})
+		require.NoError(t, err)
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{
+			"initial": {"x": {"capacity": 3, "amount": 0}, "y": {"capacity": 2, "amount": 0}},
+			"goal": {"kind": "either", "targets": [3]},
+			"solvable": true,
+			"step_count": 1,
+			"steps": [
+				{"state": {"x": {"capacity": 3, "amount": 3}, "y": {"capacity": 2, "amount": 0}}, "action": "fill_x"}
+			]
+		}`, output.String())
+	})
+
+	t.Run("json format without solution", func(t *testing.T) {
+
+		expected := func(state models.State, z int) (models.Solution, error) {
+			return models.Solution{}, models.ErrNoSolution
+		}
+		input := "3\n9\n4\n"
+		output := &bytes.Buffer{}
+		a, err := app.New(app.Configuration{
+			Input:    bytes.NewReader([]byte(input)),
+			Output:   output,
+			Silent:   true,
+			Solver:   app.SolverFun(expected),
+			Renderer: app.JSONRenderer{},
+		})
+		require.NoError(t, err)
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{
+			"initial": {"x": {"capacity": 3, "amount": 0}, "y": {"capacity": 9, "amount": 0}},
+			"goal": {"kind": "either", "targets": [4]},
+			"solvable": false,
+			"step_count": 0,
+			"steps": []
+		}`, output.String())
+	})
+
+	t.Run("three jugs x=3, y=2, z=1", func(t *testing.T) {
+
+		expected := func(state models.MultiState, z int) (models.MultiSolution, error) {
+			assert.Equal(t, models.MultiState{Jugs: []models.Jug{
+				{Capacity: 3},
+				{Capacity: 2},
+				{Capacity: 4},
+			}}, state)
+			assert.Equal(t, 1, z)
+			return models.MultiSolution{
+				Steps: []models.MultiStep{
+					{
+						State: models.MultiState{Jugs: []models.Jug{
+							{Capacity: 3, Amount: 3},
+							{Capacity: 2},
+							{Capacity: 4},
+						}},
+						Move: models.Fill(0),
+					},
+					{
+						State: models.MultiState{Jugs: []models.Jug{
+							{Capacity: 3, Amount: 1},
+							{Capacity: 2, Amount: 2},
+							{Capacity: 4},
+						}},
+						Move: models.Pour(0, 1),
+					},
+				},
+			}, nil
+		}
+		input := "3\n3\n2\n4\n1\n"
+		output := &bytes.Buffer{}
+		a, err := app.New(app.Configuration{
+			Input:       bytes.NewReader([]byte(input)),
+			Output:      output,
+			Silent:      true,
+			Solver:      app.SolverFun(unexpected),
+			MultiSolver: app.MultiSolverFun(expected),
+		})
+		require.NoError(t, err)
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assert.Equal(t, output.String(),
+			"Fill jug 1 \n(3/3, 0/2, 0/4) \n"+
+				"Transfer jug 1 to jug 2 \n(1/3, 2/2, 0/4) \n")
+	})
+
+	t.Run("two jugs are solved by the two jugs solver", func(t *testing.T) {
+
+		expected := func(state models.State, z int) (models.Solution, error) {
+			assert.Equal(t, models.State{
+				X: models.Jug{Capacity: 3},
+				Y: models.Jug{Capacity: 9},
+			}, state)
+			assert.Equal(t, 4, z)
+			return models.Solution{}, models.ErrNoSolution
+		}
+		unexpected := func(state models.MultiState, z int) (models.MultiSolution, error) {
+			t.Error("unexpected call to the multi solver")
+			return models.MultiSolution{}, nil
+		}
+		input := "2\n3\n9\n4\n"
+		output := &bytes.Buffer{}
+		a, err := app.New(app.Configuration{
+			Input:       bytes.NewReader([]byte(input)),
+			Output:      output,
+			Silent:      true,
+			Solver:      app.SolverFun(expected),
+			MultiSolver: app.MultiSolverFun(unexpected),
+		})
+		require.NoError(t, err)
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assert.Equal(t, output.String(),
+			"no solution\n")
+	})
+
+}
+
+func TestRunWith(t *testing.T) {
+
+	t.Run("happy path with x=3, y=2, z=1", func(t *testing.T) {
+
+		expected := func(state models.State, z int) (models.Solution, error) {
+			assert.Equal(t, models.State{
+				X: models.Jug{Capacity: 3},
+				Y: models.Jug{Capacity: 2},
+			}, state)
+			assert.Equal(t, 1, z)
+			return models.Solution{
+				Steps: []models.Step{
+					{
+						State: models.State{
+							X: models.Jug{Capacity: 3, Amount: 3},
+							Y: models.Jug{Capacity: 2, Amount: 0},
+						},
+						Action: models.ActionFillX,
+					},
+				},
+			}, nil
+		}
+		output := &bytes.Buffer{}
+		a, err := app.New(app.Configuration{
+			Input:  bytes.NewReader(nil),
+			Output: output,
+			Solver: app.SolverFun(expected),
+		})
+		require.NoError(t, err)
+
+		err = a.RunWith(models.State{
+			X: models.Jug{Capacity: 3},
+			Y: models.Jug{Capacity: 2},
+		}, models.AnyJug{Z: 1})
+		require.NoError(t, err)
+
+		assert.Equal(t, output.String(),
+			"Fill X \n(3/3, 0/2) \n")
+	})
+
+	t.Run("invalid parameters are not solved", func(t *testing.T) {
+
+		unexpected := func(state models.State, z int) (models.Solution, error) {
+			t.Error("unexpected call to the solver")
+			return models.Solution{}, nil
+		}
+		a, err := app.New(app.Configuration{
+			Input:  bytes.NewReader(nil),
+			Output: &bytes.Buffer{},
+			Solver: app.SolverFun(unexpected),
+		})
+		require.NoError(t, err)
+
+		invalid := []models.State{
+			{X: models.Jug{Capacity: 3}, Y: models.Jug{Capacity: 0}},
+			{X: models.Jug{Capacity: 3, Amount: 4}, Y: models.Jug{Capacity: 2}},
+		}
+		for _, state := range invalid {
+			err = a.RunWith(state, models.AnyJug{Z: 1})
+			assert.ErrorIs(t, err, app.ErrInvalidParameters)
+		}
+
+		err = a.RunWith(models.State{
+			X: models.Jug{Capacity: 3},
+			Y: models.Jug{Capacity: 2},
+		}, models.AnyJug{Z: 4})
+		assert.ErrorIs(t, err, app.ErrInvalidParameters)
+	})
+}
+
+func TestSolve(t *testing.T) {
+
+	noSolution := func(state models.State, z int) (models.Solution, error) {
+		return models.Solution{}, models.ErrNoSolution
+	}
+	output := &bytes.Buffer{}
+	a, err := app.New(app.Configuration{
+		Input:  bytes.NewReader(nil),
+		Output: output,
+		Solver: app.SolverFun(noSolution),
+	})
+	require.NoError(t, err)
+
+	state := models.State{
+		X: models.Jug{Capacity: 4},
+		Y: models.Jug{Capacity: 2},
+	}
+	result, err := a.Solve(state, models.AnyJug{Z: 3})
+	require.NoError(t, err)
+	assert.False(t, result.Solvable)
+	assert.Empty(t, output.String(), "nothing is written")
+
+	_, err = a.Solve(state, models.Sum{Z: 3})
+	assert.ErrorIs(t, err, app.ErrInvalidParameters, "there is no goal solver")
+}
+
+func TestVerify(t *testing.T) {
+
+	// wrong claims z=1 is measured by filling X.
+	wrong := func(state models.State, z int) (models.Solution, error) {
+		return models.Solution{
+			Steps: []models.Step{
+				{
+					State:  state.Apply(models.ActionFillX),
+					Action: models.ActionFillX,
+				},
+			},
+		}, nil
+	}
+	state := models.State{
+		X: models.Jug{Capacity: 3},
+		Y: models.Jug{Capacity: 2},
+	}
+
+	for _, verify := range []bool{false, true} {
+		a, err := app.New(app.Configuration{
+			Input:  bytes.NewReader(nil),
+			Output: &bytes.Buffer{},
+			Solver: app.SolverFun(wrong),
+			Verify: verify,
+		})
+		require.NoError(t, err)
+
+		err = a.RunWith(state, models.AnyJug{Z: 1})
+		if verify {
+			assert.ErrorIs(t, err, models.ErrInvalidSolution)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestCosts(t *testing.T) {
+
+	fillX := func(state models.State, z int) (models.Solution, error) {
+		return models.Solution{
+			Steps: []models.Step{
+				{
+					State:  state.Apply(models.ActionFillX),
+					Action: models.ActionFillX,
+				},
+			},
+		}, nil
+	}
+	state := models.State{
+		X: models.Jug{Capacity: 3},
+		Y: models.Jug{Capacity: 2},
+	}
+
+	a, err := app.New(app.Configuration{
+		Input:  bytes.NewReader(nil),
+		Solver: app.SolverFun(fillX),
+		Costs:  models.Costs{models.ActionFillX: 5},
+	})
+	require.NoError(t, err)
+
+	result, err := a.Solve(state, models.AnyJug{Z: 3})
+	require.NoError(t, err)
+	require.NotNil(t, result.Cost)
+	assert.Equal(t, 5, *result.Cost)
+	assert.Equal(t, 3, *result.WaterUsed)
+
+	_, err = app.New(app.Configuration{
+		Solver: app.SolverFun(fillX),
+		Costs:  models.Costs{models.ActionFillX: -1},
+	})
+	assert.Error(t, err, "negative costs are rejected")
+}
+
+func TestPuzzle(t *testing.T) {
+
+	z := 3
+	state, goal, err := app.Puzzle{X: 5, Y: 4, WY: 1, Z: &z}.Build()
+	require.NoError(t, err)
+	assert.Equal(t, models.State{
+		X: models.Jug{Capacity: 5},
+		Y: models.Jug{Capacity: 4, Amount: 1},
+	}, state)
+	assert.Equal(t, models.AnyJug{Z: 3}, goal)
+
+	_, goal, err = app.Puzzle{X: 5, Y: 4, Goal: models.GoalExact, Targets: []int{4, 0}}.Build()
+	require.NoError(t, err)
+	assert.Equal(t, models.Exact{Amounts: []int{4, 0}}, goal)
+
+	_, _, err = app.Puzzle{X: 5, Y: 4}.Build()
+	assert.ErrorIs(t, err, app.ErrInvalidParameters, "z is required")
+
+	targets, err := app.ParseTargets("4, 0")
+	require.NoError(t, err)
+	assert.Equal(t, []int{4, 0}, targets)
+
+	_, err = app.ParseTargets("4,")
+	assert.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+
+	t.Run("a solver is required", func(t *testing.T) {
+		_, err := app.New(app.Configuration{})
+		assert.Error(t, err)
+	})
+
+	t.Run("goals other than either require a goal solver", func(t *testing.T) {
+		_, err := app.New(app.Configuration{
+			Solver: app.SolverFun(func(state models.State, z int) (models.Solution, error) {
+				return models.Solution{}, nil
+			}),
+			Goal: models.GoalSum,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown goals are rejected", func(t *testing.T) {
+		_, err := app.New(app.Configuration{
+			GoalSolver: app.GoalSolverFun(func(state models.State, goal models.Goal) (models.Solution, error) {
+				return models.Solution{}, nil
+			}),
+			Goal: "half",
+		})
+		assert.Error(t, err)
+	})
 }
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// ContextSolverFun is a wrapper to simplify the ContextSolver interface
+// implementation, it is also a Solver which is never cancelled.
+type ContextSolverFun func(ctx context.Context, state models.State, z int) (models.Solution, error)
+
+// SolveContext just wraps the internal solver solve.
+func (s ContextSolverFun) SolveContext(ctx context.Context, state models.State, z int) (models.Solution, error) {
+	return s(ctx, state, z)
+}
+
+// Solve solves without a deadline.
+func (s ContextSolverFun) Solve(state models.State, z int) (models.Solution, error) {
+	return s(context.Background(), state, z)
+}
+
+// ContextSolver is a Solver which can be cancelled.
+//
+// The search must stop once ctx is done, returning an error wrapping
+// ctx.Err().
+type ContextSolver interface {
+	Solver
+	SolveContext(ctx context.Context, state models.State, z int) (models.Solution, error)
+}
+
+// WithContext adapts a Solver which is not a ContextSolver, such as a
+// SolverFun. As it cannot be stopped once it starts, ctx is only checked
+// before and after solving.
+func WithContext(s Solver) ContextSolver {
+	if cs, ok := s.(ContextSolver); ok {
+		return cs
+	}
+	return ContextSolverFun(func(ctx context.Context, state models.State, z int) (models.Solution, error) {
+		if err := ctx.Err(); err != nil {
+			return models.Solution{}, err
+		}
+		solution, err := s.Solve(state, z)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return models.Solution{}, ctxErr
+		}
+		return solution, err
+	})
+}
+
+// ContextGoalSolverFun is a wrapper to simplify the ContextGoalSolver
+// interface implementation, it is also a GoalSolver which is never cancelled.
+type ContextGoalSolverFun func(ctx context.Context, state models.State, goal models.Goal) (models.Solution, error)
+
+// SolveGoalContext just wraps the internal solver solve.
+func (s ContextGoalSolverFun) SolveGoalContext(ctx context.Context, state models.State, goal models.Goal) (models.Solution, error) {
+	return s(ctx, state, goal)
+}
+
+// SolveGoal solves without a deadline.
+func (s ContextGoalSolverFun) SolveGoal(state models.State, goal models.Goal) (models.Solution, error) {
+	return s(context.Background(), state, goal)
+}
+
+// ContextGoalSolver is a GoalSolver which can be cancelled, the same
+// constraints as ContextSolver apply.
+type ContextGoalSolver interface {
+	GoalSolver
+	SolveGoalContext(ctx context.Context, state models.State, goal models.Goal) (models.Solution, error)
+}
+
+// GoalWithContext is the WithContext counterpart for GoalSolver.
+func GoalWithContext(s GoalSolver) ContextGoalSolver {
+	if cs, ok := s.(ContextGoalSolver); ok {
+		return cs
+	}
+	return ContextGoalSolverFun(func(ctx context.Context, state models.State, goal models.Goal) (models.Solution, error) {
+		if err := ctx.Err(); err != nil {
+			return models.Solution{}, err
+		}
+		solution, err := s.SolveGoal(state, goal)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return models.Solution{}, ctxErr
+		}
+		return solution, err
+	})
+}
+
+// ContextMultiSolverFun is a wrapper to simplify the ContextMultiSolver
+// interface implementation, it is also a MultiSolver which is never cancelled.
+type ContextMultiSolverFun func(ctx context.Context, state models.MultiState, z int) (models.MultiSolution, error)
+
+// SolveMultiContext just wraps the internal solver solve.
+func (s ContextMultiSolverFun) SolveMultiContext(ctx context.Context, state models.MultiState, z int) (models.MultiSolution, error) {
+	return s(ctx, state, z)
+}
+
+// SolveMulti solves without a deadline.
+func (s ContextMultiSolverFun) SolveMulti(state models.MultiState, z int) (models.MultiSolution, error) {
+	return s(context.Background(), state, z)
+}
+
+// ContextMultiSolver is a MultiSolver which can be cancelled, the same
+// constraints as ContextSolver apply.
+type ContextMultiSolver interface {
+	MultiSolver
+	SolveMultiContext(ctx context.Context, state models.MultiState, z int) (models.MultiSolution, error)
+}
+
+// MultiWithContext is the WithContext counterpart for MultiSolver.
+func MultiWithContext(s MultiSolver) ContextMultiSolver {
+	if cs, ok := s.(ContextMultiSolver); ok {
+		return cs
+	}
+	return ContextMultiSolverFun(func(ctx context.Context, state models.MultiState, z int) (models.MultiSolution, error) {
+		if err := ctx.Err(); err != nil {
+			return models.MultiSolution{}, err
+		}
+		solution, err := s.SolveMulti(state, z)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return models.MultiSolution{}, ctxErr
+		}
+		return solution, err
+	})
+}
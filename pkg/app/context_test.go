@@ -0,0 +1,69 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+func TestWithContext(t *testing.T) {
+
+	calls := 0
+	solver := app.SolverFun(func(state models.State, z int) (models.Solution, error) {
+		calls++
+		return models.Solution{}, nil
+	})
+
+	_, err := app.WithContext(solver).SolveContext(context.Background(), models.State{}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = app.WithContext(solver).SolveContext(ctx, models.State{}, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls, "the solver is not called once ctx is done")
+
+	contextSolver := app.ContextSolverFun(func(ctx context.Context, state models.State, z int) (models.Solution, error) {
+		return models.Solution{}, nil
+	})
+	_, err = app.WithContext(contextSolver).SolveContext(ctx, models.State{}, 0)
+	assert.NoError(t, err, "context solvers are used as they are")
+}
+
+func TestTimeout(t *testing.T) {
+
+	// slow only stops searching once ctx is done.
+	slow := func(ctx context.Context, state models.State, z int) (models.Solution, error) {
+		<-ctx.Done()
+		return models.Solution{}, ctx.Err()
+	}
+	output := &bytes.Buffer{}
+	a, err := app.New(app.Configuration{
+		Input:   bytes.NewReader([]byte("3\n2\n1\n")),
+		Output:  output,
+		Silent:  true,
+		Solver:  app.ContextSolverFun(slow),
+		Timeout: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	err = a.Run()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, output.String())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = a.RunWithContext(ctx, models.State{
+		X: models.Jug{Capacity: 3},
+		Y: models.Jug{Capacity: 2},
+	}, models.AnyJug{Z: 1})
+	assert.ErrorIs(t, err, context.Canceled)
+}
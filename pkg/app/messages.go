@@ -14,9 +14,63 @@ Measure Z gallons of water using only an X-gallon and Y-gallon jug.
 	requestY = `Insert the value for the "y" jug, remember it must be positive: `
 	requestZ = `Insert the value for the "z" goal, it must be smaller than either "x" or "y": `
 
+	requestZJug       = `Insert the value for the "z" goal, it must not exceed the "%s" jug: `
+	requestZSum       = `Insert the value for the "z" goal, it must not exceed "x" + "y": `
+	requestGoalAmount = `Insert the goal amount for %s, it must not exceed its capacity: `
+
+	requestJugs   = `Insert the number of jugs, remember it must be positive: `
+	requestJug    = `Insert the value for jug %d, remember it must be positive: `
+	requestMultiZ = `Insert the value for the "z" goal, it must be smaller than at least one of the jugs: `
+	requestAmount = `Insert the starting amount for %s, it must not exceed its capacity: `
+
 	zSmaller      = "z must be smaller than either x or y"
-	zNegative     = "z must be zero or greater"
-	xyNotPositive = "both x and z must be positive"
+	zSmallerJug   = "z must not exceed the %s jug"
+	zSmallerSum   = "z must not exceed x + y"
+	xyNotPositive = "both x and y must be positive"
+	zSmallerMulti = "z must be smaller than at least one of the jugs"
+	amountTooBig  = "the amount must not exceed the jug capacity"
 
 	noSolution = "no solution"
+
+	replWelcome = `Welcome to Water Jugs Riddle Solver!
+
+Type "help" for the list of commands.
+
+`
+	replPrompt = "> "
+	replHelp   = `Commands:
+  solve X Y Z [WX WY]  solves a puzzle, starting with WX and WY in the jugs.
+                       The exact goal takes both amounts as Z, as in 4,0
+  set solver NAME      solves the next puzzles with the named solver
+  set goal KIND        what measuring z means: either, x, y, sum or exact
+  format NAME          writes the next solutions as text, json, csv or markdown
+  history              lists the puzzles solved so far
+  replay N             writes the solution of the N-th puzzle again
+  help                 writes this message
+  quit                 exits`
+	unknownCommand = "unknown command %q, type \"help\" for the list of commands"
+	commandUsage   = "usage: %s"
+	unknownSolver  = "unknown solver %q, the solvers are: %s"
+	noSolvers      = "there are no solvers to choose from"
+	noPuzzle       = "there is no puzzle %s, type \"history\" for the list of puzzles"
+	emptyHistory   = "no puzzles solved yet"
+
+	playHelp = `Commands:
+  fill x, fill y       fills a jug from the lake
+  empty x, empty y     empties a jug
+  pour x y, pour y x   pours as much water as possible from a jug to the other
+  hint                 tells the next action of the solver's solution from here
+  undo, redo           takes back the last action, or takes it again
+  help                 writes this message
+  quit                 gives up`
+	playStart      = "Measure %s. Type \"help\" for the list of commands."
+	playNoSolution = "This puzzle has no solution, there is nothing to play."
+	playWon        = "You measured %s in %d moves, the solver's solution takes %d."
+	playHint       = "Hint: %s"
+	playStuck      = "There is no solution from here, try undo."
+	playNoHint     = "There is no hint from here: %v."
+	playNothing    = "Nothing happens."
+	playNoUndo     = "There is nothing to undo."
+	playNoRedo     = "There is nothing to redo."
+	unknownPlay    = "unknown command %q, type \"help\" for the list of commands"
 )
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// playActions maps the actions typed by the user to models.Action.
+var playActions = map[string]models.Action{
+	"fill x":   models.ActionFillX,
+	"fill y":   models.ActionFillY,
+	"empty x":  models.ActionEmptyX,
+	"empty y":  models.ActionEmptyY,
+	"pour x y": models.ActionTransferY,
+	"pour y x": models.ActionTransferX,
+}
+
+// Play is a blocking operation which lets the user solve the puzzle. The
+// puzzle is requested as Run does, then the user types actions such as
+// "fill x", "empty y" or "pour x y", one per line, and the new state is
+// written after each one.
+//
+// Once the goal is reached the amount of moves is compared with the solution
+// of the GoalSolver if there is one, or the Solver otherwise. It is not the
+// shortest unless the solver finds the shortest solutions, as bfs does, so the
+// user may need fewer moves. "hint" writes the next action of that solution
+// from the current state, "undo" and "redo" take back actions or take them
+// again. Undone actions are not counted as moves.
+//
+// Play ends once the goal is reached, on "quit" or at the end of the input.
+func (a *App) Play() error {
+	return a.PlayContext(context.Background())
+}
+
+// PlayContext is Play, but solving stops with an error wrapping ctx.Err()
+// once ctx is done. Waiting for the input is not stopped.
+func (a *App) PlayContext(ctx context.Context) error {
+
+	err := a.output.Write(welcome)
+	if err != nil {
+		return err
+	}
+	initial, goal, err := a.requestPuzzle()
+	if err != nil {
+		return err
+	}
+
+	reference, err := a.reference(ctx, initial, goal)
+	if errors.Is(err, models.ErrNoSolution) {
+		return a.output.WriteLn(playNoSolution)
+	}
+	if err != nil {
+		return err
+	}
+
+	// states holds every state reached, the current one is states[moves],
+	// the ones after it can be redone.
+	states := []models.State{initial}
+	moves := 0
+	err = a.output.WriteLn(fmt.Sprintf(playStart, goal))
+	if err != nil {
+		return err
+	}
+
+	for {
+		current := states[moves]
+		if goal.Reached(current.Jugs()) {
+			return a.output.WriteLn(fmt.Sprintf(playWon, goal, moves, len(reference.Steps)))
+		}
+
+		err = a.output.Write(fmt.Sprintf("(%d/%d, %d/%d) > ",
+			current.X.Amount, current.X.Capacity,
+			current.Y.Amount, current.Y.Capacity))
+		if err != nil {
+			return err
+		}
+		line, err := a.input.Read()
+		if errors.Is(err, io.EOF) {
+			return a.output.WriteLn("")
+		}
+		if err != nil {
+			return fmt.Errorf("reading action: %w", err)
+		}
+
+		command := strings.Join(strings.Fields(strings.ToLower(line)), " ")
+		var message string
+		switch command {
+		case "":
+		case "hint":
+			message, err = a.hint(ctx, current, goal)
+			if err != nil {
+				return err
+			}
+		case "undo":
+			if moves == 0 {
+				message = playNoUndo
+				break
+			}
+			moves--
+		case "redo":
+			if moves == len(states)-1 {
+				message = playNoRedo
+				break
+			}
+			moves++
+		case "help":
+			message = playHelp
+		case "quit", "exit":
+			return nil
+		default:
+			action, ok := playActions[command]
+			if !ok {
+				message = fmt.Sprintf(unknownPlay, line)
+				break
+			}
+			next := current.Apply(action)
+			if next == current {
+				message = playNothing
+				break
+			}
+			states = append(states[:moves+1], next)
+			moves++
+		}
+
+		if message != "" {
+			if err = a.output.WriteLn(message); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// reference finds the solution the user is compared with from the state, see
+// Play.
+func (a *App) reference(ctx context.Context, state models.State, goal models.Goal) (models.Solution, error) {
+
+	if a.goalSolver == nil {
+		return a.solve(ctx, state, goal)
+	}
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+	return GoalWithContext(a.goalSolver).SolveGoalContext(ctx, state, goal)
+}
+
+// hint returns the message with the next action of the reference solution from
+// the state.
+func (a *App) hint(ctx context.Context, state models.State, goal models.Goal) (string, error) {
+
+	solution, err := a.reference(ctx, state, goal)
+	if errors.Is(err, models.ErrNoSolution) {
+		return playStuck, nil
+	}
+	if errors.Is(err, models.ErrUnsupported) {
+		return fmt.Sprintf(playNoHint, err), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("finding hint: %w", err)
+	}
+	return fmt.Sprintf(playHint, solution.Steps[0].Action), nil
+}
@@ -0,0 +1,89 @@
+package app_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/euclid"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/iterative"
+)
+
+func TestPlay(t *testing.T) {
+
+	play := func(input string) string {
+		output := &bytes.Buffer{}
+		a, err := app.New(app.Configuration{
+			Input:      strings.NewReader(input),
+			Output:     output,
+			Solver:     app.SolverFun(iterative.Solve),
+			GoalSolver: app.GoalSolverFun(bfs.SolveGoal),
+		})
+		require.NoError(t, err)
+		require.NoError(t, a.Play())
+		return output.String()
+	}
+
+	t.Run("reaching the goal", func(t *testing.T) {
+		output := play("5\n3\n4\nfill x\npour x y\nempty y\npour x y\nfill x\npour x y\n")
+
+		assert.Contains(t, output, "Measure 4 in any jug.")
+		assert.Contains(t, output, "(0/5, 0/3) > (5/5, 0/3) > (2/5, 3/3) > ")
+		assert.True(t, strings.HasSuffix(output,
+			"(5/5, 2/3) > You measured 4 in any jug in 6 moves, the solver's solution takes 6.\n"))
+	})
+
+	t.Run("undo and redo", func(t *testing.T) {
+		output := play("3\n2\n1\nfill y\nundo\nundo\nredo\nredo\nundo\nfill x\npour x y\n")
+
+		assert.Contains(t, output, "(0/3, 0/2) > (0/3, 2/2) > (0/3, 0/2) > There is nothing to undo.\n"+
+			"(0/3, 0/2) > (0/3, 2/2) > There is nothing to redo.\n"+
+			"(0/3, 2/2) > (0/3, 0/2) > (3/3, 0/2) > ")
+		assert.Contains(t, output, "in 2 moves, the solver's solution takes 2.\n", "undone actions are not moves")
+	})
+
+	t.Run("hints", func(t *testing.T) {
+		output := play("3\n2\n1\nfill y\nhint\nempty x\nfill x\nhint\nquit\n")
+
+		assert.Contains(t, output, "(0/3, 2/2) > Hint: Fill X\n")
+		assert.Contains(t, output, "Nothing happens.\n")
+		assert.Contains(t, output, "Hint: Empty Y\n")
+		assert.NotContains(t, output, "You measured")
+	})
+
+	t.Run("unknown actions", func(t *testing.T) {
+		output := play("3\n2\n1\njump\n  Fill   X \npour x y\n")
+
+		assert.Contains(t, output, `unknown command "jump"`)
+		assert.Contains(t, output, "You measured 1 in any jug in 2 moves")
+	})
+
+	t.Run("end of input", func(t *testing.T) {
+		output := play("3\n2\n1\nfill x\n")
+		assert.True(t, strings.HasSuffix(output, "(3/3, 0/2) > \n"))
+	})
+
+	t.Run("no solution", func(t *testing.T) {
+		output := play("4\n2\n1\n")
+		assert.True(t, strings.HasSuffix(output, "This puzzle has no solution, there is nothing to play.\n"))
+	})
+
+	t.Run("hints the solver does not support", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		a, err := app.New(app.Configuration{
+			Input:  strings.NewReader("5\n3\n4\nfill x\nhint\npour x y\n"),
+			Output: output,
+			Solver: app.SolverFun(euclid.Solve),
+		})
+		require.NoError(t, err)
+		require.NoError(t, a.Play())
+
+		assert.Contains(t, output.String(), "(5/5, 0/3) > There is no hint from here: ")
+		assert.Contains(t, output.String(), "jugs must start empty.\n")
+	})
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// Puzzle holds the parameters of a two jugs puzzle as they come from a
+// non-interactive input, it is meant to be deserialised.
+type Puzzle struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	// WX and WY are the starting amounts, the jugs start empty by default.
+	WX int `json:"wx"`
+	WY int `json:"wy"`
+	// Goal is the kind of goal, models.GoalAny is used by default.
+	Goal models.GoalKind `json:"goal"`
+	// Z is the single target of every goal but models.GoalExact.
+	Z *int `json:"z"`
+	// Targets replaces Z for goals with several targets, see models.NewGoal.
+	Targets []int `json:"targets"`
+}
+
+// Build returns the initial state and goal of the puzzle, an error wrapping
+// ErrInvalidParameters is returned if the goal cannot be built.
+//
+// The parameters are not checked any further, see App.Solve.
+func (p Puzzle) Build() (models.State, models.Goal, error) {
+	kind := p.Goal
+	if kind == "" {
+		kind = models.GoalAny
+	}
+	targets := p.Targets
+	if targets == nil && p.Z != nil {
+		targets = []int{*p.Z}
+	}
+	if targets == nil {
+		return models.State{}, nil, fmt.Errorf("%w: z is required", ErrInvalidParameters)
+	}
+	goal, err := models.NewGoal(kind, targets...)
+	if err != nil {
+		return models.State{}, nil, fmt.Errorf("%w: %s", ErrInvalidParameters, err)
+	}
+
+	return models.State{
+		X: models.Jug{Capacity: p.X, Amount: p.WX},
+		Y: models.Jug{Capacity: p.Y, Amount: p.WY},
+	}, goal, nil
+}
+
+// ParseTargets parses goal targets separated by commas, as in "4,0".
+func ParseTargets(z string) ([]int, error) {
+	var targets []int
+	for _, target := range strings.Split(z, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(target))
+		if err != nil {
+			return nil, fmt.Errorf("invalid z %q, a number was expected", target)
+		}
+		targets = append(targets, n)
+	}
+	return targets, nil
+}
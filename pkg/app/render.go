@@ -0,0 +1,245 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// Format names the built-in renderers.
+type Format string
+
+const (
+	// FormatText writes each step as an action followed by the jugs state,
+	// see App.Run.
+	FormatText Format = "text"
+	// FormatJSON writes a single models.Result object.
+	FormatJSON Format = "json"
+	// FormatCSV writes a row for each step, starting with the initial state.
+	FormatCSV Format = "csv"
+	// FormatMarkdown writes a table with a row for each step, starting with
+	// the initial state.
+	FormatMarkdown Format = "markdown"
+)
+
+// Formats lists every built-in format.
+var Formats = []Format{FormatText, FormatJSON, FormatCSV, FormatMarkdown}
+
+// NewRenderer returns the built-in Renderer for the format.
+func NewRenderer(format Format) (Renderer, error) {
+	switch format {
+	case FormatText:
+		return TextRenderer{}, nil
+	case FormatJSON:
+		return JSONRenderer{}, nil
+	case FormatCSV:
+		return CSVRenderer{}, nil
+	case FormatMarkdown:
+		return MarkdownRenderer{}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// RendererFun is a wrapper to simplify the Renderer interface implementation.
+type RendererFun func(w io.Writer, result models.Result) error
+
+// Render just wraps the internal render.
+func (r RendererFun) Render(w io.Writer, result models.Result) error {
+	return r(w, result)
+}
+
+// Renderer must write the result of a puzzle to w, whether it is solvable or
+// not.
+type Renderer interface {
+	Render(w io.Writer, result models.Result) error
+}
+
+// MultiRenderer is implemented by renderers which can also write the result
+// of puzzles with any number of jugs.
+//
+// Every built-in renderer implements it.
+type MultiRenderer interface {
+	RenderMulti(w io.Writer, result models.MultiResult) error
+}
+
+// TextRenderer writes each step as an action followed by the jugs state, or
+// "no solution".
+type TextRenderer struct {
+	// Summary writes the total cost and water used after the steps.
+	Summary bool
+}
+
+func (r TextRenderer) Render(w io.Writer, result models.Result) error {
+	if !result.Solvable {
+		_, err := fmt.Fprintln(w, noSolution)
+		return err
+	}
+	for _, step := range result.Steps {
+		_, err := fmt.Fprintf(w, "%s \n(%d/%d, %d/%d) \n",
+			step.Action,
+			step.State.X.Amount, step.State.X.Capacity,
+			step.State.Y.Amount, step.State.Y.Capacity)
+		if err != nil {
+			return err
+		}
+	}
+	if r.Summary && result.Cost != nil {
+		return writeSummary(w, result)
+	}
+	return nil
+}
+
+func (TextRenderer) RenderMulti(w io.Writer, result models.MultiResult) error {
+	if !result.Solvable {
+		_, err := fmt.Fprintln(w, noSolution)
+		return err
+	}
+	for _, step := range result.Steps {
+		_, err := fmt.Fprintf(w, "%s \n%s \n", step.Move, step.State)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JSONRenderer writes the result as a single line JSON object.
+type JSONRenderer struct{}
+
+func (JSONRenderer) Render(w io.Writer, result models.Result) error {
+	return json.NewEncoder(w).Encode(result)
+}
+
+func (JSONRenderer) RenderMulti(w io.Writer, result models.MultiResult) error {
+	return json.NewEncoder(w).Encode(result)
+}
+
+// CSVRenderer writes a header and a row for each step, the first row being the
+// initial state with no action. Unsolvable puzzles only have the header.
+//
+// Actions are written with their stable identifier, see models.Action.ID, and
+// jugs are numbered from 1, as in the header.
+type CSVRenderer struct{}
+
+func (CSVRenderer) Render(w io.Writer, result models.Result) error {
+	rows := [][]string{{"step", "action", "x_amount", "x_capacity", "y_amount", "y_capacity"}}
+	row := func(i int, action string, state models.State) []string {
+		return []string{
+			strconv.Itoa(i), action,
+			strconv.Itoa(state.X.Amount), strconv.Itoa(state.X.Capacity),
+			strconv.Itoa(state.Y.Amount), strconv.Itoa(state.Y.Capacity),
+		}
+	}
+
+	if result.Solvable {
+		rows = append(rows, row(0, "", result.Initial))
+		for i, step := range result.Steps {
+			rows = append(rows, row(i+1, step.Action.ID(), step.State))
+		}
+	}
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+func (CSVRenderer) RenderMulti(w io.Writer, result models.MultiResult) error {
+	header := []string{"step", "move", "from", "to"}
+	for i := range result.Initial.Jugs {
+		header = append(header,
+			fmt.Sprintf("jug_%d_amount", i+1), fmt.Sprintf("jug_%d_capacity", i+1))
+	}
+	rows := [][]string{header}
+	row := func(i int, move []string, state models.MultiState) []string {
+		r := append([]string{strconv.Itoa(i)}, move...)
+		for _, jug := range state.Jugs {
+			r = append(r, strconv.Itoa(jug.Amount), strconv.Itoa(jug.Capacity))
+		}
+		return r
+	}
+
+	if result.Solvable {
+		rows = append(rows, row(0, []string{"", "", ""}, result.Initial))
+		for i, step := range result.Steps {
+			to := ""
+			if step.Move.Kind == models.MovePour {
+				to = strconv.Itoa(step.Move.To + 1)
+			}
+			move := []string{string(step.Move.Kind), strconv.Itoa(step.Move.From + 1), to}
+			rows = append(rows, row(i+1, move, step.State))
+		}
+	}
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// MarkdownRenderer writes a table with a row for each step, the first row
+// being the initial state. Unsolvable puzzles are written as "no solution".
+type MarkdownRenderer struct {
+	// Summary writes the total cost and water used after the table.
+	Summary bool
+}
+
+func (r MarkdownRenderer) Render(w io.Writer, result models.Result) error {
+	if !result.Solvable {
+		_, err := fmt.Fprintln(w, noSolution)
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "| Step | Action | X | Y |\n|---:|---|---:|---:|\n| 0 | Start | %d/%d | %d/%d |\n",
+		result.Initial.X.Amount, result.Initial.X.Capacity,
+		result.Initial.Y.Amount, result.Initial.Y.Capacity)
+	if err != nil {
+		return err
+	}
+	for i, step := range result.Steps {
+		_, err = fmt.Fprintf(w, "| %d | %s | %d/%d | %d/%d |\n", i+1, step.Action,
+			step.State.X.Amount, step.State.X.Capacity,
+			step.State.Y.Amount, step.State.Y.Capacity)
+		if err != nil {
+			return err
+		}
+	}
+	if r.Summary && result.Cost != nil {
+		if _, err = fmt.Fprintln(w); err != nil {
+			return err
+		}
+		return writeSummary(w, result)
+	}
+	return nil
+}
+
+func (MarkdownRenderer) RenderMulti(w io.Writer, result models.MultiResult) error {
+	if !result.Solvable {
+		_, err := fmt.Fprintln(w, noSolution)
+		return err
+	}
+
+	header, align, start := "| Step | Move |", "|---:|---|", "| 0 | Start |"
+	for i, jug := range result.Initial.Jugs {
+		header += fmt.Sprintf(" %d |", i+1)
+		align += "---:|"
+		start += fmt.Sprintf(" %d/%d |", jug.Amount, jug.Capacity)
+	}
+	_, err := fmt.Fprintf(w, "%s\n%s\n%s\n", header, align, start)
+	if err != nil {
+		return err
+	}
+	for i, step := range result.Steps {
+		row := fmt.Sprintf("| %d | %s |", i+1, step.Move)
+		for _, jug := range step.State.Jugs {
+			row += fmt.Sprintf(" %d/%d |", jug.Amount, jug.Capacity)
+		}
+		_, err = fmt.Fprintln(w, row)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSummary writes the total cost and water used by a weighed solution.
+func writeSummary(w io.Writer, result models.Result) error {
+	_, err := fmt.Fprintf(w, "Total cost: %d, water used: %d\n", *result.Cost, *result.WaterUsed)
+	return err
+}
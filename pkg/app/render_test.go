@@ -0,0 +1,194 @@
+package app_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+func TestRenderers(t *testing.T) {
+
+	initial := models.State{
+		X: models.Jug{Capacity: 3},
+		Y: models.Jug{Capacity: 2},
+	}
+	solved := models.NewResult(initial, models.AnyJug{Z: 1}, models.Solution{
+		Steps: []models.Step{
+			{
+				State: models.State{
+					X: models.Jug{Capacity: 3, Amount: 3},
+					Y: models.Jug{Capacity: 2, Amount: 0},
+				},
+				Action: models.ActionFillX,
+			},
+			{
+				State: models.State{
+					X: models.Jug{Capacity: 3, Amount: 1},
+					Y: models.Jug{Capacity: 2, Amount: 2},
+				},
+				Action: models.ActionTransferY,
+			},
+		},
+	}, true)
+	unsolvable := models.NewResult(initial, models.AnyJug{Z: 4}, models.Solution{}, false)
+
+	tests := []struct {
+		format     app.Format
+		solved     string
+		unsolvable string
+	}{
+		{
+			format: app.FormatText,
+			solved: "Fill X \n(3/3, 0/2) \n" +
+				"Transfer to Y \n(1/3, 2/2) \n",
+			unsolvable: "no solution\n",
+		},
+		{
+			format: app.FormatCSV,
+			solved: "step,action,x_amount,x_capacity,y_amount,y_capacity\n" +
+				"0,,0,3,0,2\n" +
+				"1,fill_x,3,3,0,2\n" +
+				"2,transfer_y,1,3,2,2\n",
+			unsolvable: "step,action,x_amount,x_capacity,y_amount,y_capacity\n",
+		},
+		{
+			format: app.FormatMarkdown,
+			solved: "| Step | Action | X | Y |\n" +
+				"|---:|---|---:|---:|\n" +
+				"| 0 | Start | 0/3 | 0/2 |\n" +
+				"| 1 | Fill X | 3/3 | 0/2 |\n" +
+				"| 2 | Transfer to Y | 1/3 | 2/2 |\n",
+			unsolvable: "no solution\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(string(test.format), func(t *testing.T) {
+			renderer, err := app.NewRenderer(test.format)
+			require.NoError(t, err)
+
+			output := &bytes.Buffer{}
+			require.NoError(t, renderer.Render(output, solved))
+			assert.Equal(t, test.solved, output.String())
+
+			output.Reset()
+			require.NoError(t, renderer.Render(output, unsolvable))
+			assert.Equal(t, test.unsolvable, output.String())
+		})
+	}
+
+	t.Run("summary", func(t *testing.T) {
+		weighed := solved
+		weighed.Weigh(models.UnitCosts)
+
+		output := &bytes.Buffer{}
+		require.NoError(t, app.TextRenderer{Summary: true}.Render(output, weighed))
+		assert.Equal(t, "Fill X \n(3/3, 0/2) \n"+
+			"Transfer to Y \n(1/3, 2/2) \n"+
+			"Total cost: 2, water used: 3\n", output.String())
+
+		output.Reset()
+		require.NoError(t, app.MarkdownRenderer{Summary: true}.Render(output, weighed))
+		assert.Contains(t, output.String(), "| 2 | Transfer to Y | 1/3 | 2/2 |\n\nTotal cost: 2, water used: 3\n")
+
+		free := solved
+		free.Weigh(models.Costs{})
+
+		output.Reset()
+		require.NoError(t, app.TextRenderer{Summary: true}.Render(output, free))
+		assert.Contains(t, output.String(), "Total cost: 0, water used: 3\n")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := app.NewRenderer("yaml")
+		assert.Error(t, err)
+	})
+
+	t.Run("every built-in renderer writes any number of jugs", func(t *testing.T) {
+		for _, format := range app.Formats {
+			renderer, err := app.NewRenderer(format)
+			require.NoError(t, err)
+			assert.Implements(t, (*app.MultiRenderer)(nil), renderer)
+		}
+	})
+}
+
+func TestMultiRenderers(t *testing.T) {
+
+	initial := models.MultiState{Jugs: []models.Jug{{Capacity: 6}, {Capacity: 9}, {Capacity: 10}}}
+	solved := models.NewMultiResult(initial, models.AnyJug{Z: 1}, models.MultiSolution{
+		Steps: []models.MultiStep{
+			{
+				State: models.MultiState{Jugs: []models.Jug{
+					{Capacity: 6}, {Capacity: 9}, {Capacity: 10, Amount: 10},
+				}},
+				Move: models.Fill(2),
+			},
+			{
+				State: models.MultiState{Jugs: []models.Jug{
+					{Capacity: 6}, {Capacity: 9, Amount: 9}, {Capacity: 10, Amount: 1},
+				}},
+				Move: models.Pour(2, 1),
+			},
+		},
+	}, true)
+
+	output := &bytes.Buffer{}
+	require.NoError(t, app.CSVRenderer{}.RenderMulti(output, solved))
+	assert.Equal(t,
+		"step,move,from,to,jug_1_amount,jug_1_capacity,jug_2_amount,jug_2_capacity,jug_3_amount,jug_3_capacity\n"+
+			"0,,,,0,6,0,9,0,10\n"+
+			"1,fill,3,,0,6,0,9,10,10\n"+
+			"2,pour,3,2,0,6,9,9,1,10\n",
+		output.String())
+
+	output.Reset()
+	require.NoError(t, app.MarkdownRenderer{}.RenderMulti(output, solved))
+	assert.Equal(t,
+		"| Step | Move | 1 | 2 | 3 |\n"+
+			"|---:|---|---:|---:|---:|\n"+
+			"| 0 | Start | 0/6 | 0/9 | 0/10 |\n"+
+			"| 1 | Fill jug 3 | 0/6 | 0/9 | 10/10 |\n"+
+			"| 2 | Transfer jug 3 to jug 2 | 0/6 | 9/9 | 1/10 |\n",
+		output.String())
+}
+
+func TestCustomRenderer(t *testing.T) {
+
+	solver := func(state models.State, z int) (models.Solution, error) {
+		return models.Solution{}, nil
+	}
+	renderer := func(w io.Writer, result models.Result) error {
+		_, err := io.WriteString(w, "custom\n")
+		return err
+	}
+
+	output := &bytes.Buffer{}
+	a, err := app.New(app.Configuration{
+		Input:    bytes.NewReader([]byte("3\n2\n0\n")),
+		Output:   output,
+		Silent:   true,
+		Solver:   app.SolverFun(solver),
+		Renderer: app.RendererFun(renderer),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, a.Run())
+	assert.Equal(t, "custom\n", output.String())
+
+	_, err = app.New(app.Configuration{
+		Solver:      app.SolverFun(solver),
+		MultiSolver: app.MultiSolverFun(bfsUnused),
+		Renderer:    app.RendererFun(renderer),
+	})
+	assert.Error(t, err, "custom renderers cannot write any number of jugs")
+}
+
+func bfsUnused(state models.MultiState, z int) (models.MultiSolution, error) {
+	return models.MultiSolution{}, nil
+}
@@ -0,0 +1,237 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// entry is a puzzle solved by the REPL.
+type entry struct {
+	goal   models.Goal
+	result models.Result
+}
+
+// REPL is a blocking operation which reads commands from the App input, one
+// per line, until "quit" or the end of the input, solving as many puzzles as
+// requested. Solutions, the history and the help are written to the App
+// output as Run does, the rest of the messages are silenced if the App is.
+//
+// The commands are:
+//
+//	solve X Y Z [WX WY]
+//	set solver NAME
+//	set goal KIND
+//	format NAME
+//	history
+//	replay N
+//	help
+//	quit
+//
+// Commands changing the settings change the App. Invalid commands and puzzles
+// which cannot be solved are reported to the user and the REPL goes on, only
+// failing to read or write ends it with an error.
+func (a *App) REPL() error {
+	return a.REPLContext(context.Background())
+}
+
+// REPLContext is REPL, but solving stops once ctx is done. Waiting for the
+// input is not stopped.
+func (a *App) REPLContext(ctx context.Context) error {
+
+	err := a.output.Write(replWelcome)
+	if err != nil {
+		return err
+	}
+
+	var history []entry
+	for {
+		if err = a.output.Write(replPrompt); err != nil {
+			return err
+		}
+		line, err := a.input.Read()
+		if errors.Is(err, io.EOF) {
+			// The end of the input quits, leaving the prompt on its own line.
+			return a.output.WriteLn("")
+		}
+		if err != nil {
+			return fmt.Errorf("reading command: %w", err)
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var message string
+		switch command, args := fields[0], fields[1:]; command {
+		case "solve":
+			var e entry
+			e, message = a.replSolve(ctx, args)
+			if message == "" {
+				history = append(history, e)
+				if err = a.write(e.result); err != nil {
+					return err
+				}
+			}
+		case "set":
+			message = a.replSet(args)
+		case "format":
+			message = a.replFormat(args)
+		case "history":
+			if err = a.solutionOutput.WriteLn(replHistory(history)); err != nil {
+				return err
+			}
+		case "replay":
+			var e entry
+			e, message = replReplay(history, args)
+			if message == "" {
+				if err = a.write(e.result); err != nil {
+					return err
+				}
+			}
+		case "help":
+			if err = a.solutionOutput.WriteLn(replHelp); err != nil {
+				return err
+			}
+		case "quit", "exit":
+			return nil
+		default:
+			message = fmt.Sprintf(unknownCommand, command)
+		}
+
+		if message != "" {
+			if err = a.output.WriteLn(message); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// replSolve solves the puzzle given by the "solve" arguments, a message for
+// the user is returned if it cannot be solved.
+func (a *App) replSolve(ctx context.Context, args []string) (entry, string) {
+
+	if len(args) != 3 && len(args) != 5 {
+		return entry{}, fmt.Sprintf(commandUsage, "solve X Y Z [WX WY]")
+	}
+	numbers := make([]int, 0, 4)
+	for _, arg := range append(args[:2:2], args[3:]...) {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return entry{}, fmt.Sprintf("invalid number %q", arg)
+		}
+		numbers = append(numbers, n)
+	}
+	targets, err := ParseTargets(args[2])
+	if err != nil {
+		return entry{}, err.Error()
+	}
+
+	puzzle := Puzzle{X: numbers[0], Y: numbers[1], Goal: a.goal, Targets: targets}
+	if len(numbers) == 4 {
+		puzzle.WX, puzzle.WY = numbers[2], numbers[3]
+	}
+	state, goal, err := puzzle.Build()
+	if err != nil {
+		return entry{}, err.Error()
+	}
+	result, err := a.SolveContext(ctx, state, goal)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return entry{}, fmt.Sprintf("timed out after %s, before the puzzle was solved", a.timeout)
+	}
+	if err != nil {
+		return entry{}, err.Error()
+	}
+	return entry{goal: goal, result: result}, ""
+}
+
+// replSet changes the solver or the goal kind.
+func (a *App) replSet(args []string) string {
+
+	if len(args) != 2 {
+		return fmt.Sprintf(commandUsage, "set solver NAME | set goal KIND")
+	}
+	switch args[0] {
+	case "solver":
+		if len(a.solvers) == 0 {
+			return noSolvers
+		}
+		solver, ok := a.solvers[args[1]]
+		if !ok {
+			names := make([]string, 0, len(a.solvers))
+			for name := range a.solvers {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return fmt.Sprintf(unknownSolver, args[1], strings.Join(names, ", "))
+		}
+		a.solver, a.goalSolver = nil, solver
+	case "goal":
+		kind := models.GoalKind(args[1])
+		if _, err := models.NewGoal(kind, 0); err != nil {
+			return err.Error()
+		}
+		if kind != models.GoalAny && a.goalSolver == nil {
+			return fmt.Sprintf("goal %s is not supported", kind)
+		}
+		a.goal = kind
+	default:
+		return fmt.Sprintf(commandUsage, "set solver NAME | set goal KIND")
+	}
+	return ""
+}
+
+// replFormat changes the renderer the solutions are written with.
+func (a *App) replFormat(args []string) string {
+
+	if len(args) != 1 {
+		return fmt.Sprintf(commandUsage, "format NAME")
+	}
+	renderer, err := NewRenderer(Format(args[0]))
+	if err != nil {
+		return err.Error()
+	}
+	a.renderer = renderer
+	return ""
+}
+
+// replHistory lists the puzzles solved so far, numbered from 1.
+func replHistory(history []entry) string {
+
+	if len(history) == 0 {
+		return emptyHistory
+	}
+	lines := make([]string, len(history))
+	for i, e := range history {
+		outcome := noSolution
+		if e.result.Solvable {
+			outcome = fmt.Sprintf("%d steps", e.result.StepCount)
+		}
+		initial := e.result.Initial
+		lines[i] = fmt.Sprintf("%d: (%d/%d, %d/%d), %s: %s", i+1,
+			initial.X.Amount, initial.X.Capacity,
+			initial.Y.Amount, initial.Y.Capacity,
+			e.goal, outcome)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// replReplay returns the N-th puzzle of the history, a message for the user is
+// returned if there is no such puzzle.
+func replReplay(history []entry, args []string) (entry, string) {
+
+	if len(args) != 1 {
+		return entry{}, fmt.Sprintf(commandUsage, "replay N")
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(history) {
+		return entry{}, fmt.Sprintf(noPuzzle, args[0])
+	}
+	return history[n-1], ""
+}
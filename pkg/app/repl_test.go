@@ -0,0 +1,91 @@
+package app_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/iterative"
+)
+
+func TestREPL(t *testing.T) {
+
+	newREPL := func(input string, silent bool) (app.App, *bytes.Buffer) {
+		output := &bytes.Buffer{}
+		a, err := app.New(app.Configuration{
+			Input:      strings.NewReader(input),
+			Output:     output,
+			Silent:     silent,
+			Solver:     app.SolverFun(iterative.Solve),
+			GoalSolver: app.GoalSolverFun(bfs.SolveGoal),
+			Solvers: map[string]app.GoalSolver{
+				"bfs":       app.GoalSolverFun(bfs.SolveGoal),
+				"iterative": app.GoalSolverFun(iterative.SolveGoal),
+			},
+		})
+		require.NoError(t, err)
+		return a, output
+	}
+
+	t.Run("several puzzles until quit", func(t *testing.T) {
+		a, output := newREPL("solve 3 2 1\n\nsolve 3 9 4\nformat csv\nsolve 5 4 5\nquit\nsolve 3 2 1\n", true)
+		require.NoError(t, a.REPL())
+
+		assert.Equal(t, "Fill X \n(3/3, 0/2) \nTransfer to Y \n(1/3, 2/2) \n"+
+			"no solution\n"+
+			"step,action,x_amount,x_capacity,y_amount,y_capacity\n0,,0,5,0,4\n1,fill_x,5,5,0,4\n",
+			output.String())
+	})
+
+	t.Run("end of input quits", func(t *testing.T) {
+		a, output := newREPL("solve 5 4 5", false)
+		require.NoError(t, a.REPL())
+		assert.True(t, strings.HasSuffix(output.String(), "> \n"), "the unfinished line is not run")
+
+		a, _ = newREPL("", false)
+		assert.NoError(t, a.REPL())
+	})
+
+	t.Run("history and replay", func(t *testing.T) {
+		a, output := newREPL("solve 3 2 1 1 0\nsolve 3 9 4\nhistory\nformat json\nreplay 2\nreplay 3\n", false)
+		require.NoError(t, a.REPL())
+
+		assert.Contains(t, output.String(),
+			"> 1: (1/3, 0/2), 1 in any jug: 0 steps\n2: (0/3, 0/9), 4 in any jug: no solution\n")
+		assert.Contains(t, output.String(), `"solvable":false`)
+		assert.Contains(t, output.String(), "there is no puzzle 3")
+
+		a, output = newREPL("history\nsolve 3 2 1\nhistory\nhelp\n", true)
+		require.NoError(t, a.REPL())
+
+		assert.True(t, strings.HasPrefix(output.String(), "no puzzles solved yet\n"), "silent REPLs list them too")
+		assert.Contains(t, output.String(), "\n1: (0/3, 0/2), 1 in any jug: 2 steps\nCommands:\n")
+	})
+
+	t.Run("settings", func(t *testing.T) {
+		a, output := newREPL("set goal sum\nsolve 3 2 5\nset goal half\nset solver iterative\nsolve 3 2 5\n"+
+			"set solver dfs\nset goal y\nsolve 3 2 2\n", false)
+		require.NoError(t, a.REPL())
+
+		assert.Contains(t, output.String(), "Fill Y \n(3/3, 2/2) \n")
+		assert.Contains(t, output.String(), "unknown goal")
+		assert.Contains(t, output.String(), "unsupported by the solver: goal 5 in total")
+		assert.Contains(t, output.String(), `unknown solver "dfs", the solvers are: bfs, iterative`)
+		assert.Contains(t, output.String(), "Fill Y \n(0/3, 2/2) \n")
+	})
+
+	t.Run("invalid commands do not end the REPL", func(t *testing.T) {
+		a, output := newREPL("jump\nsolve 3\nsolve 3 2 x\nsolve 3 2 9\nformat yaml\nsolve 3 2 1\n", false)
+		require.NoError(t, a.REPL())
+
+		assert.Contains(t, output.String(), `unknown command "jump"`)
+		assert.Contains(t, output.String(), "usage: solve X Y Z [WX WY]")
+		assert.Contains(t, output.String(), "z must be smaller than either x or y")
+		assert.Contains(t, output.String(), "Transfer to Y \n(1/3, 2/2) \n")
+	})
+}
@@ -0,0 +1,332 @@
+// Package batch solves many puzzles from a stream, one puzzle per line.
+package batch
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// Format is the format of both the input and output streams.
+type Format string
+
+const (
+	// FormatCSV reads lines as in "x,y,z" or "x,y,z,wx,wy", z may be quoted
+	// to hold several targets, as in "5,4,\"4,0\"". A first line starting
+	// with "x" is taken as a header and skipped.
+	//
+	// A row is written for each line with the columns in csvHeader, the
+	// actions are their identifiers separated by spaces.
+	FormatCSV Format = "csv"
+	// FormatJSONL reads an app.Puzzle JSON object per line and writes a
+	// Record JSON object per line.
+	FormatJSONL Format = "jsonl"
+)
+
+// Formats lists every format.
+var Formats = []Format{FormatCSV, FormatJSONL}
+
+var csvHeader = []string{"line", "x", "y", "wx", "wy", "goal", "targets", "solvable", "step_count", "actions", "error"}
+
+// Record is the outcome of a single input line, either a Result or an Error.
+type Record struct {
+	// Line is the line number of the puzzle in the input, starting at 1.
+	Line   int            `json:"line"`
+	Result *models.Result `json:"result,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// Configuration is the base configuration for running a batch.
+type Configuration struct {
+	// Input is read until EOF, empty lines are skipped. If nil, stdin is
+	// used as default.
+	Input io.Reader
+	// Output gets a record for each non empty input line, in input order. If
+	// nil, stdout is used as default.
+	Output io.Writer
+	// Format is used for both Input and Output, FormatCSV is used by default.
+	Format Format
+	// Workers bounds the puzzles being solved at the same time, the number
+	// of CPUs is used by default.
+	Workers int
+	// Solver and GoalSolver solve the puzzles, see app.Configuration.
+	Solver     app.Solver
+	GoalSolver app.GoalSolver
+	// Goal is the goal kind of the puzzles which do not set one, as CSV
+	// lines, models.GoalAny is used by default.
+	Goal models.GoalKind
+	// Timeout bounds how long solving each puzzle may take, see
+	// app.Configuration.
+	Timeout time.Duration
+}
+
+// job is an input line waiting to be solved, its record is sent to done.
+type job struct {
+	line int
+	text string
+	done chan Record
+}
+
+// Run solves every puzzle in the input and writes their records to the
+// output. A line which cannot be parsed or solved gets a record with an Error
+// and the run goes on, only reading and writing errors stop it.
+func Run(conf Configuration) error {
+
+	input := conf.Input
+	if input == nil {
+		input = os.Stdin
+	}
+	output := conf.Output
+	if output == nil {
+		output = os.Stdout
+	}
+	format := conf.Format
+	if format == "" {
+		format = FormatCSV
+	}
+	if format != FormatCSV && format != FormatJSONL {
+		return fmt.Errorf("unknown format %q", format)
+	}
+	workers := conf.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	goal := conf.Goal
+	if goal == "" {
+		goal = models.GoalAny
+	}
+	if _, err := models.NewGoal(goal, 0); err != nil {
+		return err
+	}
+
+	a, err := app.New(app.Configuration{
+		Output:     io.Discard,
+		Input:      strings.NewReader(""),
+		Silent:     true,
+		Solver:     conf.Solver,
+		GoalSolver: conf.GoalSolver,
+		Timeout:    conf.Timeout,
+	})
+	if err != nil {
+		return err
+	}
+	solver := solver{app: &a, format: format, goal: goal}
+
+	// Records are written in the order jobs are queued on pending, which
+	// also bounds the lines being held in memory.
+	jobs := make(chan job)
+	pending := make(chan chan Record, 2*workers)
+	stop := make(chan struct{})
+	readErr := make(chan error, 1)
+
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		readErr <- read(input, format, func(j job) bool {
+			select {
+			case pending <- j.done:
+			case <-stop:
+				return false
+			}
+			jobs <- j
+			return true
+		})
+	}()
+	for i := 0; i < workers; i++ {
+		go func() {
+			for j := range jobs {
+				j.done <- solver.solve(j.line, j.text)
+			}
+		}()
+	}
+
+	w := newWriter(output, format)
+	err = w.header()
+	if err != nil {
+		close(stop)
+	}
+	for done := range pending {
+		record := <-done
+		if err != nil {
+			continue
+		}
+		err = w.write(record)
+		if err != nil {
+			close(stop)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("writing record: %w", err)
+	}
+	if err = w.flush(); err != nil {
+		return fmt.Errorf("writing record: %w", err)
+	}
+	if err = <-readErr; err != nil {
+		return fmt.Errorf("reading input: %w", err)
+	}
+	return nil
+}
+
+// read calls queue for each non empty line of the input until it returns
+// false.
+func read(input io.Reader, format Format, queue func(job) bool) error {
+	scanner := bufio.NewScanner(input)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if line == 1 && format == FormatCSV && strings.HasPrefix(strings.ToLower(text), "x") {
+			continue
+		}
+		if !queue(job{line: line, text: text, done: make(chan Record, 1)}) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+type solver struct {
+	app    *app.App
+	format Format
+	goal   models.GoalKind
+}
+
+// solve parses and solves a single line.
+func (s solver) solve(line int, text string) Record {
+	record := Record{Line: line}
+
+	puzzle, err := s.parse(text)
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+	if puzzle.Goal == "" {
+		puzzle.Goal = s.goal
+	}
+	state, goal, err := puzzle.Build()
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+	result, err := s.app.Solve(state, goal)
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+	record.Result = &result
+	return record
+}
+
+func (s solver) parse(text string) (app.Puzzle, error) {
+	var puzzle app.Puzzle
+	if s.format == FormatJSONL {
+		decoder := json.NewDecoder(strings.NewReader(text))
+		decoder.DisallowUnknownFields()
+		err := decoder.Decode(&puzzle)
+		if err != nil {
+			return app.Puzzle{}, fmt.Errorf("invalid puzzle: %w", err)
+		}
+		return puzzle, nil
+	}
+
+	fields, err := csv.NewReader(strings.NewReader(text)).Read()
+	if err != nil {
+		return app.Puzzle{}, fmt.Errorf("invalid puzzle: %w", err)
+	}
+	if len(fields) != 3 && len(fields) != 5 {
+		return app.Puzzle{}, errors.New("invalid puzzle: x,y,z or x,y,z,wx,wy was expected")
+	}
+	numbers := []struct {
+		name  string
+		field int
+		n     *int
+	}{{"x", 0, &puzzle.X}, {"y", 1, &puzzle.Y}, {"wx", 3, &puzzle.WX}, {"wy", 4, &puzzle.WY}}
+	for _, number := range numbers {
+		if number.field >= len(fields) {
+			break
+		}
+		field := fields[number.field]
+		*number.n, err = strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return app.Puzzle{}, fmt.Errorf("invalid %s %q, a number was expected", number.name, field)
+		}
+	}
+	puzzle.Targets, err = app.ParseTargets(fields[2])
+	if err != nil {
+		return app.Puzzle{}, err
+	}
+	return puzzle, nil
+}
+
+// writer writes records in the batch format.
+type writer struct {
+	format Format
+	json   *json.Encoder
+	csv    *csv.Writer
+}
+
+func newWriter(output io.Writer, format Format) writer {
+	if format == FormatJSONL {
+		return writer{format: format, json: json.NewEncoder(output)}
+	}
+	return writer{format: format, csv: csv.NewWriter(output)}
+}
+
+func (w writer) header() error {
+	if w.format == FormatJSONL {
+		return nil
+	}
+	return w.csv.Write(csvHeader)
+}
+
+func (w writer) write(record Record) error {
+	if w.format == FormatJSONL {
+		return w.json.Encode(record)
+	}
+
+	row := make([]string, len(csvHeader))
+	row[0] = strconv.Itoa(record.Line)
+	row[len(row)-1] = record.Error
+	if r := record.Result; r != nil {
+		row[1] = strconv.Itoa(r.Initial.X.Capacity)
+		row[2] = strconv.Itoa(r.Initial.Y.Capacity)
+		row[3] = strconv.Itoa(r.Initial.X.Amount)
+		row[4] = strconv.Itoa(r.Initial.Y.Amount)
+		if r.Goal != nil {
+			row[5] = string(r.Goal.Kind)
+			targets := make([]string, len(r.Goal.Targets))
+			for i, target := range r.Goal.Targets {
+				targets[i] = strconv.Itoa(target)
+			}
+			row[6] = strings.Join(targets, ",")
+		}
+		row[7] = strconv.FormatBool(r.Solvable)
+		row[8] = strconv.Itoa(r.StepCount)
+		actions := make([]string, len(r.Steps))
+		for i, step := range r.Steps {
+			actions[i] = step.Action.ID()
+		}
+		row[9] = strings.Join(actions, " ")
+	}
+	return w.csv.Write(row)
+}
+
+func (w writer) flush() error {
+	if w.format == FormatJSONL {
+		return nil
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
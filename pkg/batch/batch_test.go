@@ -0,0 +1,135 @@
+package batch_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/batch"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/iterative"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+func TestRun(t *testing.T) {
+
+	t.Run("csv", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		err := batch.Run(batch.Configuration{
+			Input: strings.NewReader("x,y,z\n" +
+				"5,4,3\n" +
+				"\n" +
+				"4,2,3\n" +
+				"5,4,9\n" +
+				"five,4,3\n" +
+				"5,4,5,0,4\n"),
+			Output: output,
+			Solver: app.SolverFun(iterative.Solve),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			"line,x,y,wx,wy,goal,targets,solvable,step_count,actions,error\n"+
+				"2,5,4,0,0,either,3,true,4,fill_y transfer_x fill_y transfer_x,\n"+
+				"4,4,2,0,0,either,3,false,0,,\n"+
+				"5,,,,,,,,,,invalid parameters: z must be smaller than either x or y\n"+
+				"6,,,,,,,,,,\"invalid x \"\"five\"\", a number was expected\"\n"+
+				"7,5,4,0,4,either,5,true,1,fill_x,\n",
+			output.String())
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		err := batch.Run(batch.Configuration{
+			Input: strings.NewReader(`{"x":5,"y":4,"z":3}` + "\n" +
+				`{"x":5,"y":4,"targets":[4,0],"goal":"exact"}` + "\n" +
+				`{"x":5,` + "\n"),
+			Output:     output,
+			Format:     batch.FormatJSONL,
+			Solver:     app.SolverFun(iterative.Solve),
+			GoalSolver: app.GoalSolverFun(bfs.SolveGoal),
+		})
+		require.NoError(t, err)
+		assert.NotContains(t, output.String(), "cost", "costs are only written when asked for")
+
+		records := decodeRecords(t, output)
+		require.Len(t, records, 3)
+		assert.Equal(t, 4, records[0].Result.StepCount)
+		assert.Equal(t, models.GoalExact, records[1].Result.Goal.Kind)
+		assert.True(t, records[1].Result.Solvable)
+		assert.Nil(t, records[2].Result)
+		assert.NotEmpty(t, records[2].Error)
+	})
+
+	t.Run("goal for every line", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		err := batch.Run(batch.Configuration{
+			Input:      strings.NewReader("5,4,9\n5,4,\"4,0\"\n"),
+			Output:     output,
+			GoalSolver: app.GoalSolverFun(bfs.SolveGoal),
+			Goal:       models.GoalSum,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			"line,x,y,wx,wy,goal,targets,solvable,step_count,actions,error\n"+
+				"1,5,4,0,0,sum,9,true,2,fill_x fill_y,\n"+
+				"2,,,,,,,,,,invalid parameters: goal sum requires a single target\n",
+			output.String())
+	})
+
+	t.Run("records are in input order", func(t *testing.T) {
+		// Earlier lines take longer, so they finish last.
+		solver := func(state models.State, z int) (models.Solution, error) {
+			time.Sleep(time.Duration(10-state.X.Capacity) * time.Millisecond)
+			return iterative.Solve(state, z)
+		}
+		input := &strings.Builder{}
+		for x := 1; x <= 9; x++ {
+			fmt.Fprintf(input, "%d,1,1\n", x)
+		}
+
+		output := &bytes.Buffer{}
+		err := batch.Run(batch.Configuration{
+			Input:   strings.NewReader(input.String()),
+			Output:  output,
+			Format:  batch.FormatCSV,
+			Workers: 4,
+			Solver:  app.SolverFun(solver),
+		})
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+		require.Len(t, lines, 10)
+		for i, line := range lines[1:] {
+			assert.True(t, strings.HasPrefix(line, fmt.Sprintf("%d,%d,1,", i+1, i+1)), line)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		err := batch.Run(batch.Configuration{
+			Input:  strings.NewReader(""),
+			Format: "yaml",
+			Solver: app.SolverFun(iterative.Solve),
+		})
+		assert.Error(t, err)
+	})
+}
+
+func decodeRecords(t *testing.T, output *bytes.Buffer) []batch.Record {
+	var records []batch.Record
+	decoder := json.NewDecoder(output)
+	for decoder.More() {
+		var record batch.Record
+		require.NoError(t, decoder.Decode(&record))
+		records = append(records, record)
+	}
+	return records
+}
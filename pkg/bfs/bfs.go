@@ -0,0 +1,139 @@
+// Package bfs implements a breadth first search solution to the water jug
+// puzzle.
+//
+// Every (w_x, w_y) tuple is a node of a graph, and every models.Action taken
+// on a node is an edge to the node it leads to.
+//
+// Let's take x = 3, y = 2 as an example, starting from (0, 0), though any
+// starting amounts are valid.
+//
+// Fill X -> (3, 0)
+// Fill Y -> (0, 2)
+//
+// Those are the only new nodes at distance 1, emptying or transferring does
+// nothing. From (3, 0) and (0, 2) we reach the nodes at distance 2.
+//
+// Fill Y -> (3, 2)
+// Transfer to Y -> (1, 2)
+// Transfer to X -> (2, 0)
+//
+// Since the nodes are visited in order of distance, the first node meeting
+// the winning condition is reached with the least possible amount of actions.
+// The winning condition can be any models.Goal, as every node is checked.
+//
+// The graph has at most (x+1)*(y+1) nodes, once every reachable node is
+// visited without meeting the winning condition we know there is no solution.
+package bfs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// edge indicates the node and the action that discovered a node.
+type edge struct {
+	from   models.State
+	action models.Action
+}
+
+// Solve solves the water jugs riddle with the least amount of steps, starting
+// from the amounts in the baseState.
+//
+// An error ErrNoSolution is returned if no solution exists, a
+// *models.AmountError is returned if the starting amounts are invalid.
+func Solve(baseState models.State, z int) (models.Solution, error) {
+	return SolveContext(context.Background(), baseState, z)
+}
+
+// SolveContext is Solve, but it stops with ctx.Err() once ctx is done.
+func SolveContext(ctx context.Context, baseState models.State, z int) (models.Solution, error) {
+
+	x := baseState.X.Capacity
+	y := baseState.Y.Capacity
+	if z > x && z > y {
+		return models.Solution{}, errors.New("z must be smaller than either x or y")
+	}
+	if z < 0 {
+		return models.Solution{}, errors.New("z must be zero or greater")
+	}
+
+	return SolveGoalContext(ctx, baseState, models.AnyJug{Z: z})
+}
+
+// SolveGoal solves the water jugs riddle with the least amount of steps,
+// starting from the amounts in the baseState, until the goal is reached.
+//
+// An error ErrNoSolution is returned if no solution exists, a
+// *models.AmountError is returned if the starting amounts are invalid.
+func SolveGoal(baseState models.State, goal models.Goal) (models.Solution, error) {
+	return SolveGoalContext(context.Background(), baseState, goal)
+}
+
+// SolveGoalContext is SolveGoal, but it stops with ctx.Err() once ctx is done,
+// which is checked before visiting every node.
+func SolveGoalContext(ctx context.Context, baseState models.State, goal models.Goal) (models.Solution, error) {
+
+	if baseState.Y.Capacity <= 0 || baseState.X.Capacity <= 0 {
+		return models.Solution{}, errors.New("both x and y must be positive")
+	}
+	if err := baseState.CheckAmounts(); err != nil {
+		return models.Solution{}, err
+	}
+	if err := goal.Validate(baseState.Jugs()); err != nil {
+		return models.Solution{}, err
+	}
+
+	won := func(s models.State) bool {
+		return goal.Reached(s.Jugs())
+	}
+
+	start := baseState
+	if won(start) {
+		return models.Solution{}, nil
+	}
+
+	discoveredBy := map[models.State]edge{start: {}}
+	queue := []models.State{start}
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return models.Solution{}, err
+		}
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, action := range models.Actions {
+			next := current.Apply(action)
+			if _, ok := discoveredBy[next]; ok {
+				continue
+			}
+			discoveredBy[next] = edge{from: current, action: action}
+
+			if won(next) {
+				return path(start, next, discoveredBy), nil
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	return models.Solution{}, models.ErrNoSolution
+}
+
+// path walks back from the end node to the start node, building the Solution
+// in the right order.
+func path(start, end models.State, discoveredBy map[models.State]edge) models.Solution {
+
+	var steps []models.Step
+	for current := end; current != start; current = discoveredBy[current].from {
+		steps = append(steps, models.Step{
+			State:  current,
+			Action: discoveredBy[current].action,
+		})
+	}
+
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return models.Solution{Steps: steps}
+}
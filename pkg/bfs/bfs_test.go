@@ -0,0 +1,285 @@
+package bfs_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/iterative"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvertest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoSolution(t *testing.T) {
+
+	_, err := bfs.Solve(solvertest.NewState(9, 3), 4)
+
+	assert.ErrorIs(t, err, models.ErrNoSolution)
+}
+
+func TestInvalid(t *testing.T) {
+
+	t.Run("x should be positive", func(t *testing.T) {
+		_, err := bfs.Solve(solvertest.NewState(-5, 3), 4)
+		assert.Error(t, err)
+	})
+
+	t.Run("y should be positive", func(t *testing.T) {
+		_, err := bfs.Solve(solvertest.NewState(5, -3), 4)
+		assert.Error(t, err)
+	})
+
+	t.Run("z should be zero or greater", func(t *testing.T) {
+		_, err := bfs.Solve(solvertest.NewState(5, 3), -4)
+		assert.Error(t, err)
+	})
+
+	t.Run("z should be lower than either x or y", func(t *testing.T) {
+		_, err := bfs.Solve(solvertest.NewState(5, 3), 10)
+		assert.Error(t, err)
+	})
+
+	t.Run("amounts should be between 0 and the capacity", func(t *testing.T) {
+		state := solvertest.NewState(5, 3)
+		state.Y.Amount = 4
+		_, err := bfs.Solve(state, 1)
+
+		var amountErr *models.AmountError
+		assert.ErrorAs(t, err, &amountErr)
+	})
+}
+
+func TestSolutions(t *testing.T) {
+
+	t.Run("simple solution, should fill Y", func(t *testing.T) {
+		solution, err := bfs.Solve(solvertest.NewState(5, 4), 3)
+		require.NoError(t, err)
+
+		expectedSolution := models.Solution{
+			Steps: []models.Step{
+				{
+					State: models.State{
+						X: models.Jug{Capacity: 5, Amount: 0},
+						Y: models.Jug{Capacity: 4, Amount: 4},
+					},
+					Action: models.ActionFillY,
+				},
+				{
+					State: models.State{
+						X: models.Jug{Capacity: 5, Amount: 4},
+						Y: models.Jug{Capacity: 4, Amount: 0},
+					},
+					Action: models.ActionTransferX,
+				},
+				{
+					State: models.State{
+						X: models.Jug{Capacity: 5, Amount: 4},
+						Y: models.Jug{Capacity: 4, Amount: 4},
+					},
+					Action: models.ActionFillY,
+				},
+				{
+					State: models.State{
+						X: models.Jug{Capacity: 5, Amount: 5},
+						Y: models.Jug{Capacity: 4, Amount: 3},
+					},
+					Action: models.ActionTransferX,
+				},
+			},
+		}
+
+		assert.Equal(t, expectedSolution, solution)
+	})
+
+	t.Run("z = 0 should be measurable in 0 steps", func(t *testing.T) {
+
+		s, err := bfs.Solve(solvertest.NewState(5, 3), 0)
+		require.NoError(t, err)
+		assert.Len(t, s.Steps, 0)
+	})
+}
+
+func TestGoals(t *testing.T) {
+
+	t.Run("sum of both jugs", func(t *testing.T) {
+		solution, err := bfs.SolveGoal(solvertest.NewState(5, 3), models.Sum{Z: 8})
+		require.NoError(t, err)
+
+		expectedSolution := models.Solution{
+			Steps: []models.Step{
+				{
+					State: models.State{
+						X: models.Jug{Capacity: 5, Amount: 5},
+						Y: models.Jug{Capacity: 3, Amount: 0},
+					},
+					Action: models.ActionFillX,
+				},
+				{
+					State: models.State{
+						X: models.Jug{Capacity: 5, Amount: 5},
+						Y: models.Jug{Capacity: 3, Amount: 3},
+					},
+					Action: models.ActionFillY,
+				},
+			},
+		}
+
+		assert.Equal(t, expectedSolution, solution)
+	})
+
+	t.Run("exact state", func(t *testing.T) {
+		goal := models.Exact{Amounts: []int{4, 0}}
+		solution, err := bfs.SolveGoal(solvertest.NewState(5, 3), goal)
+		require.NoError(t, err)
+
+		assert.Len(t, solution.Steps, 7)
+		assertReachesGoal(t, solvertest.NewState(5, 3), goal, solution)
+	})
+
+	t.Run("exact state without solution", func(t *testing.T) {
+		_, err := bfs.SolveGoal(solvertest.NewState(5, 3), models.Exact{Amounts: []int{4, 2}})
+		assert.ErrorIs(t, err, models.ErrNoSolution)
+	})
+
+	t.Run("sum should not exceed both jugs", func(t *testing.T) {
+		_, err := bfs.SolveGoal(solvertest.NewState(5, 3), models.Sum{Z: 9})
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, models.ErrNoSolution)
+	})
+}
+
+// TestNeverLongerThanIterative cross-checks both solvers, the breadth first
+// search must agree on whether there is a solution and must never take more
+// steps.
+func TestNeverLongerThanIterative(t *testing.T) {
+
+	for x := 1; x <= 15; x++ {
+		for y := 1; y <= 15; y++ {
+			for z := 0; z <= x || z <= y; z++ {
+				t.Run(fmt.Sprintf("x=%d, y=%d, z=%d", x, y, z), func(t *testing.T) {
+					expected, expectedErr := iterative.Solve(solvertest.NewState(x, y), z)
+					solution, err := bfs.Solve(solvertest.NewState(x, y), z)
+
+					if expectedErr != nil {
+						assert.ErrorIs(t, err, models.ErrNoSolution)
+						return
+					}
+					require.NoError(t, err)
+					assert.LessOrEqual(t, len(solution.Steps), len(expected.Steps))
+					assertReplays(t, solvertest.NewState(x, y), z, solution)
+				})
+			}
+		}
+	}
+}
+
+// TestStartingAmounts cross-checks both solvers when the jugs do not start
+// empty, they must agree on whether there is a solution and the breadth first
+// search must never take more steps.
+func TestStartingAmounts(t *testing.T) {
+
+	for x := 1; x <= 8; x++ {
+		for y := 1; y <= 8; y++ {
+			for a := 0; a <= x; a++ {
+				for b := 0; b <= y; b++ {
+					for z := 0; z <= x || z <= y; z++ {
+						state := models.State{
+							X: models.Jug{Capacity: x, Amount: a},
+							Y: models.Jug{Capacity: y, Amount: b},
+						}
+						t.Run(fmt.Sprintf("x=%d/%d, y=%d/%d, z=%d", a, x, b, y, z), func(t *testing.T) {
+							expected, expectedErr := iterative.Solve(state, z)
+							solution, err := bfs.Solve(state, z)
+
+							if expectedErr != nil {
+								assert.ErrorIs(t, err, models.ErrNoSolution)
+								return
+							}
+							require.NoError(t, err)
+							assert.LessOrEqual(t, len(solution.Steps), len(expected.Steps))
+							assertReplays(t, state, z, solution)
+							assertReplays(t, state, z, expected)
+						})
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestInJugSameAsIterative cross-checks both solvers when z must be measured
+// in a specific jug, they must agree on whether there is a solution and the
+// breadth first search must never take more steps.
+func TestInJugSameAsIterative(t *testing.T) {
+
+	for x := 1; x <= 8; x++ {
+		for y := 1; y <= 8; y++ {
+			for a := 0; a <= x; a++ {
+				for b := 0; b <= y; b++ {
+					for z := 0; z <= x; z++ {
+						state := models.State{
+							X: models.Jug{Capacity: x, Amount: a},
+							Y: models.Jug{Capacity: y, Amount: b},
+						}
+						goal := models.InJug{Jug: models.JugX, Z: z}
+						t.Run(fmt.Sprintf("x=%d/%d, y=%d/%d, %s", a, x, b, y, goal), func(t *testing.T) {
+							expected, expectedErr := iterative.SolveGoal(state, goal)
+							solution, err := bfs.SolveGoal(state, goal)
+
+							if expectedErr != nil {
+								assert.ErrorIs(t, err, models.ErrNoSolution)
+								return
+							}
+							require.NoError(t, err)
+							assert.LessOrEqual(t, len(solution.Steps), len(expected.Steps))
+							assertReachesGoal(t, state, goal, solution)
+							assertReachesGoal(t, state, goal, expected)
+						})
+					}
+				}
+			}
+		}
+	}
+}
+
+// assertReplays checks every step follows from the previous one and that the
+// last one measures z.
+func assertReplays(t *testing.T, state models.State, z int, solution models.Solution) {
+	t.Helper()
+	assertReachesGoal(t, state, models.AnyJug{Z: z}, solution)
+}
+
+// assertReachesGoal checks every step follows from the previous one and that
+// the last one reaches the goal.
+func assertReachesGoal(t *testing.T, state models.State, goal models.Goal, solution models.Solution) {
+	t.Helper()
+	require.NoError(t, models.Validate(state, goal, solution))
+}
+
+func TestContext(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := bfs.SolveContext(ctx, solvertest.NewState(1_000_003, 1_000_033), 2)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = bfs.SolveGoalContext(ctx, solvertest.NewState(1_000_003, 1_000_033), models.Sum{Z: 3})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = bfs.SolveMultiContext(ctx, newMultiState(1_003, 1_033, 1_051), 2)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestConformance(t *testing.T) {
+	solvertest.TestSolver(t, app.SolverFun(bfs.Solve))
+}
+
+func FuzzSolve(f *testing.F) {
+	solvertest.FuzzSolver(f, app.SolverFun(bfs.Solve))
+}
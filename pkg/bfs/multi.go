@@ -0,0 +1,127 @@
+package bfs
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// multiEdge indicates the node and the move that discovered a node.
+type multiEdge struct {
+	from models.MultiState
+	move models.Move
+}
+
+// SolveMulti solves the water jugs riddle for any number of jugs with the
+// least amount of steps, starting from the amounts in the baseState. z must be
+// measured in any of them.
+//
+// It explores the same graph as Solve, but every (w_1, ..., w_n) tuple is a
+// node and the edges are every models.Move.
+//
+// An error ErrNoSolution is returned if no solution exists, a
+// *models.AmountError is returned if the starting amounts are invalid.
+func SolveMulti(baseState models.MultiState, z int) (models.MultiSolution, error) {
+	return SolveMultiGoalContext(context.Background(), baseState, models.AnyJug{Z: z})
+}
+
+// SolveMultiContext is SolveMulti, but it stops with ctx.Err() once ctx is
+// done.
+func SolveMultiContext(ctx context.Context, baseState models.MultiState, z int) (models.MultiSolution, error) {
+	return SolveMultiGoalContext(ctx, baseState, models.AnyJug{Z: z})
+}
+
+// SolveMultiGoal solves the water jugs riddle for any number of jugs with the
+// least amount of steps, starting from the amounts in the baseState, until the
+// goal is reached.
+//
+// An error ErrNoSolution is returned if no solution exists, a
+// *models.AmountError is returned if the starting amounts are invalid.
+func SolveMultiGoal(baseState models.MultiState, goal models.Goal) (models.MultiSolution, error) {
+	return SolveMultiGoalContext(context.Background(), baseState, goal)
+}
+
+// SolveMultiGoalContext is SolveMultiGoal, but it stops with ctx.Err() once
+// ctx is done, which is checked before visiting every node.
+func SolveMultiGoalContext(ctx context.Context, baseState models.MultiState, goal models.Goal) (models.MultiSolution, error) {
+
+	if len(baseState.Jugs) == 0 {
+		return models.MultiSolution{}, errors.New("there must be at least one jug")
+	}
+	for _, jug := range baseState.Jugs {
+		if jug.Capacity <= 0 {
+			return models.MultiSolution{}, errors.New("every jug capacity must be positive")
+		}
+	}
+	if err := baseState.CheckAmounts(); err != nil {
+		return models.MultiSolution{}, err
+	}
+	if err := goal.Validate(baseState.Jugs); err != nil {
+		return models.MultiSolution{}, err
+	}
+
+	won := func(s models.MultiState) bool {
+		return goal.Reached(s.Jugs)
+	}
+
+	start := baseState
+	if won(start) {
+		return models.MultiSolution{}, nil
+	}
+
+	moves := start.Moves()
+	discoveredBy := map[string]multiEdge{key(start): {}}
+	queue := []models.MultiState{start}
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return models.MultiSolution{}, err
+		}
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, move := range moves {
+			next := current.Apply(move)
+			if _, ok := discoveredBy[key(next)]; ok {
+				continue
+			}
+			discoveredBy[key(next)] = multiEdge{from: current, move: move}
+
+			if won(next) {
+				return multiPath(start, next, discoveredBy), nil
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	return models.MultiSolution{}, models.ErrNoSolution
+}
+
+// key identifies a node by the amounts in every jug, as slices cannot be used
+// as map keys.
+func key(s models.MultiState) string {
+	buf := make([]byte, 0, len(s.Jugs)*binary.MaxVarintLen64)
+	for _, jug := range s.Jugs {
+		buf = binary.AppendVarint(buf, int64(jug.Amount))
+	}
+	return string(buf)
+}
+
+// multiPath walks back from the end node to the start node, building the
+// MultiSolution in the right order.
+func multiPath(start, end models.MultiState, discoveredBy map[string]multiEdge) models.MultiSolution {
+
+	var steps []models.MultiStep
+	for current := end; key(current) != key(start); current = discoveredBy[key(current)].from {
+		steps = append(steps, models.MultiStep{
+			State: current,
+			Move:  discoveredBy[key(current)].move,
+		})
+	}
+
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return models.MultiSolution{Steps: steps}
+}
@@ -0,0 +1,154 @@
+package bfs_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvertest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiNoSolution(t *testing.T) {
+
+	_, err := bfs.SolveMulti(newMultiState(4, 6, 8), 3)
+
+	assert.ErrorIs(t, err, models.ErrNoSolution)
+}
+
+func TestMultiInvalid(t *testing.T) {
+
+	t.Run("there should be jugs", func(t *testing.T) {
+		_, err := bfs.SolveMulti(newMultiState(), 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("every jug should be positive", func(t *testing.T) {
+		_, err := bfs.SolveMulti(newMultiState(5, 3, 0), 2)
+		assert.Error(t, err)
+	})
+
+	t.Run("z should be zero or greater", func(t *testing.T) {
+		_, err := bfs.SolveMulti(newMultiState(5, 3, 2), -4)
+		assert.Error(t, err)
+	})
+
+	t.Run("z should be lower than any jug", func(t *testing.T) {
+		_, err := bfs.SolveMulti(newMultiState(5, 3, 2), 10)
+		assert.Error(t, err)
+	})
+
+	t.Run("amounts should be between 0 and the capacity", func(t *testing.T) {
+		state := newMultiState(5, 3, 2)
+		state.Jugs[2].Amount = 3
+		_, err := bfs.SolveMulti(state, 1)
+
+		var amountErr *models.AmountError
+		require.ErrorAs(t, err, &amountErr)
+		assert.Equal(t, "3", amountErr.Jug)
+	})
+}
+
+func TestMultiSolutions(t *testing.T) {
+
+	t.Run("a third jug makes it solvable", func(t *testing.T) {
+		solution, err := bfs.SolveMulti(newMultiState(6, 9, 10), 1)
+		require.NoError(t, err)
+
+		expectedSolution := models.MultiSolution{
+			Steps: []models.MultiStep{
+				{
+					State: models.MultiState{Jugs: []models.Jug{
+						{Capacity: 6, Amount: 0},
+						{Capacity: 9, Amount: 0},
+						{Capacity: 10, Amount: 10},
+					}},
+					Move: models.Fill(2),
+				},
+				{
+					State: models.MultiState{Jugs: []models.Jug{
+						{Capacity: 6, Amount: 0},
+						{Capacity: 9, Amount: 9},
+						{Capacity: 10, Amount: 1},
+					}},
+					Move: models.Pour(2, 1),
+				},
+			},
+		}
+
+		assert.Equal(t, expectedSolution, solution)
+	})
+
+	t.Run("a single jug can only measure its capacity", func(t *testing.T) {
+		solution, err := bfs.SolveMulti(newMultiState(5), 5)
+		require.NoError(t, err)
+		assert.Len(t, solution.Steps, 1)
+
+		_, err = bfs.SolveMulti(newMultiState(5), 3)
+		assert.ErrorIs(t, err, models.ErrNoSolution)
+	})
+
+	t.Run("starting amounts should be honored", func(t *testing.T) {
+		state := newMultiState(6, 9, 10)
+		state.Jugs[1].Amount = 9
+		state.Jugs[2].Amount = 2
+
+		solution, err := bfs.SolveMulti(state, 1)
+		require.NoError(t, err)
+
+		expectedSolution := models.MultiSolution{
+			Steps: []models.MultiStep{
+				{
+					State: models.MultiState{Jugs: []models.Jug{
+						{Capacity: 6, Amount: 0},
+						{Capacity: 9, Amount: 1},
+						{Capacity: 10, Amount: 10},
+					}},
+					Move: models.Pour(1, 2),
+				},
+			},
+		}
+
+		assert.Equal(t, expectedSolution, solution)
+	})
+
+	t.Run("z = 0 should be measurable in 0 steps", func(t *testing.T) {
+
+		s, err := bfs.SolveMulti(newMultiState(5, 3, 2), 0)
+		require.NoError(t, err)
+		assert.Len(t, s.Steps, 0)
+	})
+}
+
+// TestMultiSameAsTwoJugs checks that two jugs are just a special case, both
+// searches must take the same amount of steps.
+func TestMultiSameAsTwoJugs(t *testing.T) {
+
+	for x := 1; x <= 10; x++ {
+		for y := 1; y <= 10; y++ {
+			for z := 0; z <= x || z <= y; z++ {
+				t.Run(fmt.Sprintf("x=%d, y=%d, z=%d", x, y, z), func(t *testing.T) {
+					expected, expectedErr := bfs.Solve(solvertest.NewState(x, y), z)
+					solution, err := bfs.SolveMulti(solvertest.NewState(x, y).Multi(), z)
+
+					assert.Equal(t, expectedErr, err)
+					assert.Len(t, solution.Steps, len(expected.Steps))
+					if err == nil {
+						assert.NoError(t, models.ValidateMulti(solvertest.NewState(x, y).Multi(), models.AnyJug{Z: z}, solution))
+					}
+				})
+			}
+		}
+	}
+}
+
+func newMultiState(capacities ...int) models.MultiState {
+	state := models.MultiState{}
+	for _, capacity := range capacities {
+		state.Jugs = append(state.Jugs, models.Jug{Capacity: capacity})
+	}
+	return state
+}
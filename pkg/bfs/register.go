@@ -0,0 +1,13 @@
+package bfs
+
+import "github.com/nacho692/live-free-or-die-jugging/pkg/solvers"
+
+func init() {
+	solvers.Register(solvers.Registration{
+		Name:        "bfs",
+		Description: "breadth first search, finds the shortest solution to any goal and number of jugs",
+		Solve:       SolveContext,
+		SolveGoal:   SolveGoalContext,
+		SolveMulti:  SolveMultiContext,
+	})
+}
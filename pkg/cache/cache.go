@@ -0,0 +1,279 @@
+// Package cache memoizes the solutions of a solver, so puzzles solved before
+// are answered without solving them again.
+//
+// Solutions are kept in memory, up to a size, evicting the least recently used
+// ones first. A Store, such as a FileStore, may back the memory so solutions
+// survive restarts. Puzzles without a solution are cached as well, any other
+// error is not, as it may not happen again.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// DefaultSize is the amount of solutions kept in memory if the size is not
+// configured.
+const DefaultSize = 1024
+
+// Entry is a cached solution, Steps is nil if the puzzle has no solution.
+type Entry struct {
+	Solvable bool          `json:"solvable"`
+	Steps    []models.Step `json:"steps"`
+}
+
+// Store persists entries by key, keys are opaque strings built by the Cache.
+//
+// It must be safe for concurrent use.
+type Store interface {
+	// Get returns the entry for the key, ok is false if there is none.
+	Get(key string) (entry Entry, ok bool, err error)
+	// Put stores the entry for the key.
+	Put(key string, entry Entry) error
+}
+
+// Stats counts how many puzzles were answered from the cache.
+type Stats struct {
+	// Hits are the puzzles answered from memory or from the Store.
+	Hits int64 `json:"hits"`
+	// Misses are the puzzles which had to be solved.
+	Misses int64 `json:"misses"`
+}
+
+// Configuration is the base configuration for instantiating a Cache.
+type Configuration struct {
+	// Solver solves the models.AnyJug goal, it is optional if GoalSolver is
+	// set.
+	Solver app.Solver
+	// GoalSolver solves every other goal, and models.AnyJug too if there is
+	// no Solver.
+	GoalSolver app.GoalSolver
+	// Size is the amount of solutions kept in memory, DefaultSize is used if
+	// it is zero.
+	Size int
+	// Store is optional, if set, solutions missing from memory are looked
+	// up in it and every new solution is put in it.
+	Store Store
+	// Name identifies the solvers, as in "bfs". It prefixes every key, so
+	// caches of different solvers backed by the same Store do not answer
+	// each other's puzzles. It is required along with a Store.
+	Name string
+}
+
+// Cache is an app.ContextSolver and an app.ContextGoalSolver which remembers
+// the solutions of the configured solvers, keyed by the capacities, the
+// starting amounts and the goal.
+//
+// It is safe for concurrent use as long as the solvers are. The same puzzle
+// requested at the same time may be solved more than once.
+type Cache struct {
+	solver     app.Solver
+	goalSolver app.GoalSolver
+	store      Store
+	name       string
+	size       int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// recent holds the keys from the most recently used to the least.
+	recent *list.List
+	stats  Stats
+}
+
+// item is an element of Cache.recent.
+type item struct {
+	key   string
+	entry Entry
+}
+
+// New instantiates a new Cache.
+func New(conf Configuration) (*Cache, error) {
+
+	if conf.Solver == nil && conf.GoalSolver == nil {
+		return nil, errors.New("solver cannot be nil")
+	}
+	if conf.Size < 0 {
+		return nil, errors.New("size must be zero or greater")
+	}
+	if conf.Store != nil && conf.Name == "" {
+		return nil, errors.New("name cannot be empty along with a store")
+	}
+	size := conf.Size
+	if size == 0 {
+		size = DefaultSize
+	}
+
+	return &Cache{
+		solver:     conf.Solver,
+		goalSolver: conf.GoalSolver,
+		store:      conf.Store,
+		name:       conf.Name,
+		size:       size,
+		entries:    map[string]*list.Element{},
+		recent:     list.New(),
+	}, nil
+}
+
+// Solve returns the cached solution to measure z in either jug, solving the
+// puzzle if there is none.
+func (c *Cache) Solve(state models.State, z int) (models.Solution, error) {
+	return c.SolveContext(context.Background(), state, z)
+}
+
+// SolveContext is Solve, but solving stops once ctx is done.
+func (c *Cache) SolveContext(ctx context.Context, state models.State, z int) (models.Solution, error) {
+	return c.SolveGoalContext(ctx, state, models.AnyJug{Z: z})
+}
+
+// SolveGoal returns the cached solution to reach the goal, solving the
+// puzzle if there is none. Goals which are not built-in are never cached.
+func (c *Cache) SolveGoal(state models.State, goal models.Goal) (models.Solution, error) {
+	return c.SolveGoalContext(context.Background(), state, goal)
+}
+
+// SolveGoalContext is SolveGoal, but solving stops once ctx is done.
+func (c *Cache) SolveGoalContext(ctx context.Context, state models.State, goal models.Goal) (models.Solution, error) {
+
+	spec, ok := models.SpecOf(goal)
+	if !ok {
+		return c.solve(ctx, state, goal)
+	}
+	k := key(c.name, state, spec)
+
+	entry, ok, err := c.get(k)
+	if err != nil {
+		return models.Solution{}, err
+	}
+	if !ok {
+		solution, err := c.solve(ctx, state, goal)
+		solvable := err == nil
+		if err != nil && !errors.Is(err, models.ErrNoSolution) {
+			return models.Solution{}, err
+		}
+		entry = Entry{Solvable: solvable, Steps: solution.Steps}
+		if err = c.put(k, entry); err != nil {
+			return models.Solution{}, err
+		}
+	}
+
+	if !entry.Solvable {
+		return models.Solution{}, models.ErrNoSolution
+	}
+	// The steps are copied so callers cannot change the cached ones.
+	return models.Solution{Steps: append([]models.Step(nil), entry.Steps...)}, nil
+}
+
+// Stats returns how many puzzles were answered from the cache so far.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Len returns the amount of solutions kept in memory.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.recent.Len()
+}
+
+// solve uses the Solver for the original riddle if there is one, the
+// GoalSolver otherwise.
+func (c *Cache) solve(ctx context.Context, state models.State, goal models.Goal) (models.Solution, error) {
+	if g, ok := goal.(models.AnyJug); ok && c.solver != nil {
+		return app.WithContext(c.solver).SolveContext(ctx, state, g.Z)
+	}
+	if c.goalSolver == nil {
+		return models.Solution{}, fmt.Errorf("%w: goal %s", models.ErrUnsupported, goal)
+	}
+	return app.GoalWithContext(c.goalSolver).SolveGoalContext(ctx, state, goal)
+}
+
+// get looks the key up in memory and then in the Store, counting a hit or a
+// miss.
+func (c *Cache) get(k string) (Entry, bool, error) {
+
+	c.mu.Lock()
+	if e, ok := c.entries[k]; ok {
+		c.recent.MoveToFront(e)
+		c.stats.Hits++
+		c.mu.Unlock()
+		return e.Value.(*item).entry, true, nil
+	}
+	c.mu.Unlock()
+
+	if c.store != nil {
+		entry, ok, err := c.store.Get(k)
+		if err != nil {
+			return Entry{}, false, fmt.Errorf("reading cache store: %w", err)
+		}
+		if ok {
+			c.mu.Lock()
+			c.stats.Hits++
+			c.remember(k, entry)
+			c.mu.Unlock()
+			return entry, true, nil
+		}
+	}
+
+	c.mu.Lock()
+	c.stats.Misses++
+	c.mu.Unlock()
+	return Entry{}, false, nil
+}
+
+// put stores a new entry in memory and in the Store.
+func (c *Cache) put(k string, entry Entry) error {
+
+	c.mu.Lock()
+	c.remember(k, entry)
+	c.mu.Unlock()
+
+	if c.store != nil {
+		if err := c.store.Put(k, entry); err != nil {
+			return fmt.Errorf("writing cache store: %w", err)
+		}
+	}
+	return nil
+}
+
+// remember keeps the entry in memory, evicting the least recently used one if
+// there is no room. c.mu must be held.
+func (c *Cache) remember(k string, entry Entry) {
+
+	if e, ok := c.entries[k]; ok {
+		e.Value.(*item).entry = entry
+		c.recent.MoveToFront(e)
+		return
+	}
+	c.entries[k] = c.recent.PushFront(&item{key: k, entry: entry})
+	if c.recent.Len() > c.size {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		delete(c.entries, oldest.Value.(*item).key)
+	}
+}
+
+// key identifies a puzzle solved by the named solvers, as in
+// "bfs:5/0,4/1:either:3", or "5/0,4/1:either:3" if there is no name.
+func key(name string, state models.State, spec models.GoalSpec) string {
+	targets := make([]string, len(spec.Targets))
+	for i, target := range spec.Targets {
+		targets[i] = strconv.Itoa(target)
+	}
+	k := fmt.Sprintf("%d/%d,%d/%d:%s:%s",
+		state.X.Capacity, state.X.Amount, state.Y.Capacity, state.Y.Amount,
+		spec.Kind, strings.Join(targets, ","))
+	if name == "" {
+		return k
+	}
+	return name + ":" + k
+}
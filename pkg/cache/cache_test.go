@@ -0,0 +1,265 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/cache"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvertest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// counting wraps bfs.SolveGoal, counting how many times it solves.
+type counting struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (c *counting) SolveGoal(state models.State, goal models.Goal) (models.Solution, error) {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+	return bfs.SolveGoal(state, goal)
+}
+
+func TestCache(t *testing.T) {
+
+	t.Run("solutions are solved once", func(t *testing.T) {
+		solver := &counting{}
+		c, err := cache.New(cache.Configuration{GoalSolver: solver})
+		require.NoError(t, err)
+
+		expected, err := bfs.Solve(solvertest.NewState(5, 3), 4)
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			solution, err := c.Solve(solvertest.NewState(5, 3), 4)
+			require.NoError(t, err)
+			assert.Equal(t, expected, solution)
+		}
+		assert.Equal(t, 1, solver.calls)
+		assert.Equal(t, cache.Stats{Hits: 2, Misses: 1}, c.Stats())
+	})
+
+	t.Run("puzzles without a solution are cached", func(t *testing.T) {
+		solver := &counting{}
+		c, err := cache.New(cache.Configuration{GoalSolver: solver})
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			_, err = c.Solve(solvertest.NewState(9, 3), 4)
+			assert.ErrorIs(t, err, models.ErrNoSolution)
+		}
+		assert.Equal(t, 1, solver.calls)
+	})
+
+	t.Run("keys tell puzzles apart", func(t *testing.T) {
+		solver := &counting{}
+		c, err := cache.New(cache.Configuration{GoalSolver: solver})
+		require.NoError(t, err)
+
+		state := solvertest.NewState(5, 3)
+		withWater := solvertest.NewState(5, 3)
+		withWater.X.Amount = 1
+		for _, puzzle := range []struct {
+			state models.State
+			goal  models.Goal
+		}{
+			{state, models.AnyJug{Z: 1}},
+			{state, models.AnyJug{Z: 2}},
+			{state, models.InJug{Jug: models.JugX, Z: 1}},
+			{state, models.Sum{Z: 1}},
+			{withWater, models.AnyJug{Z: 1}},
+			{solvertest.NewState(3, 5), models.AnyJug{Z: 1}},
+		} {
+			_, err = c.SolveGoal(puzzle.state, puzzle.goal)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 6, solver.calls)
+		assert.Equal(t, int64(0), c.Stats().Hits)
+	})
+
+	t.Run("the least recently used solution is evicted", func(t *testing.T) {
+		solver := &counting{}
+		c, err := cache.New(cache.Configuration{GoalSolver: solver, Size: 2})
+		require.NoError(t, err)
+
+		for _, z := range []int{1, 2, 1, 3, 1, 2} {
+			_, err = c.Solve(solvertest.NewState(5, 3), z)
+			require.NoError(t, err)
+		}
+		// 2 is evicted by 3, as 1 was used later.
+		assert.Equal(t, 4, solver.calls)
+		assert.Equal(t, 2, c.Len())
+	})
+
+	t.Run("other errors are not cached", func(t *testing.T) {
+		failures := 0
+		c, err := cache.New(cache.Configuration{
+			Solver: app.SolverFun(func(state models.State, z int) (models.Solution, error) {
+				failures++
+				return models.Solution{}, errors.New("out of water")
+			}),
+		})
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			_, err = c.Solve(solvertest.NewState(5, 3), 4)
+			assert.EqualError(t, err, "out of water")
+		}
+		assert.Equal(t, 2, failures)
+
+		_, err = c.SolveGoal(solvertest.NewState(5, 3), models.Sum{Z: 4})
+		assert.ErrorIs(t, err, models.ErrUnsupported, "there is no goal solver")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = c.SolveContext(ctx, solvertest.NewState(5, 3), 1)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("cached steps cannot be changed", func(t *testing.T) {
+		c, err := cache.New(cache.Configuration{GoalSolver: &counting{}})
+		require.NoError(t, err)
+
+		solution, err := c.Solve(solvertest.NewState(5, 3), 4)
+		require.NoError(t, err)
+		solution.Steps[0].Action = models.ActionEmptyX
+
+		solution, err = c.Solve(solvertest.NewState(5, 3), 4)
+		require.NoError(t, err)
+		assert.NoError(t, models.Validate(solvertest.NewState(5, 3), models.AnyJug{Z: 4}, solution))
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		c, err := cache.New(cache.Configuration{GoalSolver: &counting{}, Size: 8})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for z := 0; z <= 12; z++ {
+					state := solvertest.NewState(7+i%3, 12)
+					solution, err := c.Solve(state, z)
+					if errors.Is(err, models.ErrNoSolution) {
+						continue
+					}
+					assert.NoError(t, err)
+					assert.NoError(t, models.Validate(state, models.AnyJug{Z: z}, solution))
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		stats := c.Stats()
+		assert.Equal(t, int64(8*13), stats.Hits+stats.Misses)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, err := cache.New(cache.Configuration{})
+		assert.Error(t, err)
+
+		_, err = cache.New(cache.Configuration{GoalSolver: &counting{}, Size: -1})
+		assert.Error(t, err)
+
+		store, err := cache.OpenFileStore(filepath.Join(t.TempDir(), "cache.jsonl"))
+		require.NoError(t, err)
+		defer store.Close()
+		_, err = cache.New(cache.Configuration{GoalSolver: &counting{}, Store: store})
+		assert.Error(t, err, "stores require a name")
+	})
+}
+
+func TestFileStore(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "cache.jsonl")
+
+	t.Run("solutions survive restarts", func(t *testing.T) {
+		store, err := cache.OpenFileStore(path)
+		require.NoError(t, err)
+		solver := &counting{}
+		c, err := cache.New(cache.Configuration{GoalSolver: solver, Store: store, Name: "counting"})
+		require.NoError(t, err)
+
+		expected, err := c.Solve(solvertest.NewState(5, 3), 4)
+		require.NoError(t, err)
+		_, err = c.Solve(solvertest.NewState(9, 3), 4)
+		require.ErrorIs(t, err, models.ErrNoSolution)
+		require.NoError(t, store.Close())
+
+		store, err = cache.OpenFileStore(path)
+		require.NoError(t, err)
+		defer store.Close()
+		assert.Equal(t, 2, store.Len())
+		c, err = cache.New(cache.Configuration{GoalSolver: solver, Store: store, Name: "counting"})
+		require.NoError(t, err)
+
+		solution, err := c.Solve(solvertest.NewState(5, 3), 4)
+		require.NoError(t, err)
+		assert.Equal(t, expected, solution)
+		_, err = c.Solve(solvertest.NewState(9, 3), 4)
+		assert.ErrorIs(t, err, models.ErrNoSolution)
+
+		assert.Equal(t, 2, solver.calls)
+		assert.Equal(t, cache.Stats{Hits: 2}, c.Stats())
+	})
+
+	t.Run("solvers do not share solutions", func(t *testing.T) {
+		store, err := cache.OpenFileStore(path)
+		require.NoError(t, err)
+		defer store.Close()
+		solver := &counting{}
+		c, err := cache.New(cache.Configuration{GoalSolver: solver, Store: store, Name: "other"})
+		require.NoError(t, err)
+
+		_, err = c.Solve(solvertest.NewState(5, 3), 4)
+		require.NoError(t, err)
+		assert.Equal(t, 1, solver.calls)
+		assert.Equal(t, 3, store.Len())
+	})
+
+	t.Run("unfinished lines are dropped", func(t *testing.T) {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		require.NoError(t, err)
+		_, err = file.WriteString(`{"key":"counting:5/0,3/0:either:`)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		store, err := cache.OpenFileStore(path)
+		require.NoError(t, err)
+		require.NoError(t, store.Put("new", cache.Entry{}))
+		require.NoError(t, store.Close())
+
+		store, err = cache.OpenFileStore(path)
+		require.NoError(t, err)
+		defer store.Close()
+		assert.Equal(t, 4, store.Len())
+		_, ok, err := store.Get("new")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("invalid files are rejected", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "invalid.jsonl")
+		require.NoError(t, os.WriteFile(invalid, []byte("not json\n"), 0o644))
+
+		_, err := cache.OpenFileStore(invalid)
+		assert.Error(t, err)
+	})
+}
+
+func TestConformance(t *testing.T) {
+	c, err := cache.New(cache.Configuration{GoalSolver: app.GoalSolverFun(bfs.SolveGoal), Size: 64})
+	require.NoError(t, err)
+	solvertest.TestSolver(t, c)
+}
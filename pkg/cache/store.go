@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// FileStore is a Store backed by a file, where every entry is appended as a
+// JSON line. Only the offset of each entry is kept in memory, entries are read
+// back from the file when requested.
+//
+// A line left unfinished, as when the process is killed while writing it, is
+// dropped the next time the file is opened.
+type FileStore struct {
+	mu      sync.Mutex
+	file    *os.File
+	offsets map[string]int64
+	size    int64
+}
+
+// line is an entry as it is written to the file.
+type line struct {
+	Key string `json:"key"`
+	Entry
+}
+
+// OpenFileStore opens the file at path, creating it if it does not exist,
+// and indexes the entries already in it. Later entries replace earlier ones
+// with the same key.
+func OpenFileStore(path string) (*FileStore, error) {
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileStore{file: file, offsets: map[string]int64{}}
+	r := bufio.NewReader(file)
+	for {
+		data, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		var l line
+		if err = json.Unmarshal(data, &l); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("invalid cache entry at offset %d: %w", s.size, err)
+		}
+		s.offsets[l.Key] = s.size
+		s.size += int64(len(data))
+	}
+
+	// An unfinished last line is dropped, so new entries start on their own
+	// line.
+	if err = file.Truncate(s.size); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get reads the entry for the key from the file.
+func (s *FileStore) Get(key string) (Entry, bool, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offset, ok := s.offsets[key]
+	if !ok {
+		return Entry{}, false, nil
+	}
+	data, err := bufio.NewReader(io.NewSectionReader(s.file, offset, s.size-offset)).ReadBytes('\n')
+	if err != nil {
+		return Entry{}, false, err
+	}
+	var l line
+	if err = json.Unmarshal(data, &l); err != nil {
+		return Entry{}, false, err
+	}
+	return l.Entry, true, nil
+}
+
+// Put appends the entry for the key to the file.
+func (s *FileStore) Put(key string, entry Entry) error {
+
+	data, err := json.Marshal(line{Key: key, Entry: entry})
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, err := s.file.WriteAt(data, s.size)
+	if err != nil {
+		// Whatever was written is overwritten by the next entry.
+		return err
+	}
+	s.offsets[key] = s.size
+	s.size += int64(n)
+	return nil
+}
+
+// Len returns the amount of entries in the file.
+func (s *FileStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.offsets)
+}
+
+// Close closes the file, the FileStore cannot be used afterwards.
+func (s *FileStore) Close() error {
+	return s.file.Close()
+}
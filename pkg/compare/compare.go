@@ -0,0 +1,195 @@
+// Package compare runs several solvers over the same puzzles, reporting how
+// long their solutions are, how fast they found them and whether they agree on
+// which puzzles can be solved.
+//
+// Solvers are run one at a time and one puzzle at a time, so runtimes and
+// allocations are not skewed by each other.
+package compare
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"time"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// Puzzle is a puzzle starting from empty jugs.
+type Puzzle struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	Z int `json:"z"`
+}
+
+// Solver is a solver to compare, its Name tells its runs apart.
+type Solver struct {
+	Name   string
+	Solver app.Solver
+}
+
+// Run is the outcome of a solver on a puzzle, averaged over the repetitions.
+type Run struct {
+	Solver   string `json:"solver"`
+	Solvable bool   `json:"solvable"`
+	Steps    int    `json:"steps"`
+	// Duration is how long a single solve took.
+	Duration time.Duration `json:"duration_ns"`
+	// Allocs and Bytes are the heap allocations of a single solve.
+	Allocs uint64 `json:"allocs"`
+	Bytes  uint64 `json:"bytes"`
+	// Error is set if the solver failed for other reasons than the puzzle
+	// having no solution, the run is then left out of the totals.
+	Error string `json:"error,omitempty"`
+}
+
+// Comparison is the outcome of every solver on a puzzle.
+type Comparison struct {
+	Puzzle
+	Runs []Run `json:"runs"`
+	// Disagreement is set if the solvers which did not fail disagree on
+	// whether the puzzle can be solved.
+	Disagreement bool `json:"disagreement"`
+}
+
+// Total sums the runs of a solver over every puzzle.
+type Total struct {
+	Solver   string        `json:"solver"`
+	Solved   int           `json:"solved"`
+	Failed   int           `json:"failed"`
+	Steps    int           `json:"steps"`
+	Duration time.Duration `json:"duration_ns"`
+	Allocs   uint64        `json:"allocs"`
+	Bytes    uint64        `json:"bytes"`
+}
+
+// Report is the outcome of a comparison.
+type Report struct {
+	Comparisons   []Comparison `json:"comparisons"`
+	Totals        []Total      `json:"totals"`
+	Disagreements int          `json:"disagreements"`
+}
+
+// Configuration is the base configuration for comparing solvers.
+type Configuration struct {
+	// Solvers are compared in order.
+	Solvers []Solver
+	Puzzles []Puzzle
+	// Repeat solves every puzzle this many times with each solver, to even
+	// out runtimes. Once is used by default.
+	Repeat int
+}
+
+// Compare runs every solver over every puzzle.
+func Compare(conf Configuration) (Report, error) {
+
+	if len(conf.Solvers) == 0 {
+		return Report{}, errors.New("no solvers to compare")
+	}
+	for _, s := range conf.Solvers {
+		if s.Solver == nil {
+			return Report{}, fmt.Errorf("solver %q cannot be nil", s.Name)
+		}
+	}
+	repeat := conf.Repeat
+	if repeat <= 0 {
+		repeat = 1
+	}
+
+	report := Report{
+		Comparisons: make([]Comparison, 0, len(conf.Puzzles)),
+		Totals:      make([]Total, len(conf.Solvers)),
+	}
+	for i, s := range conf.Solvers {
+		report.Totals[i].Solver = s.Name
+	}
+	for _, puzzle := range conf.Puzzles {
+		comparison := Comparison{Puzzle: puzzle, Runs: make([]Run, len(conf.Solvers))}
+		solvable := map[bool]bool{}
+		for i, s := range conf.Solvers {
+			run := measure(s, puzzle, repeat)
+			comparison.Runs[i] = run
+
+			total := &report.Totals[i]
+			if run.Error != "" {
+				total.Failed++
+				continue
+			}
+			solvable[run.Solvable] = true
+			if run.Solvable {
+				total.Solved++
+			}
+			total.Steps += run.Steps
+			total.Duration += run.Duration
+			total.Allocs += run.Allocs
+			total.Bytes += run.Bytes
+		}
+		if len(solvable) > 1 {
+			comparison.Disagreement = true
+			report.Disagreements++
+		}
+		report.Comparisons = append(report.Comparisons, comparison)
+	}
+	return report, nil
+}
+
+// measure solves the puzzle repeat times, averaging the runtime and
+// allocations.
+func measure(s Solver, puzzle Puzzle, repeat int) Run {
+	state := models.State{
+		X: models.Jug{Capacity: puzzle.X},
+		Y: models.Jug{Capacity: puzzle.Y},
+	}
+
+	var (
+		solution models.Solution
+		err      error
+		before   runtime.MemStats
+		after    runtime.MemStats
+	)
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < repeat; i++ {
+		solution, err = s.Solver.Solve(state, puzzle.Z)
+	}
+	duration := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	run := Run{
+		Solver:   s.Name,
+		Duration: duration / time.Duration(repeat),
+		Allocs:   (after.Mallocs - before.Mallocs) / uint64(repeat),
+		Bytes:    (after.TotalAlloc - before.TotalAlloc) / uint64(repeat),
+	}
+	switch {
+	case errors.Is(err, models.ErrNoSolution):
+	case err != nil:
+		run.Error = err.Error()
+	default:
+		run.Solvable = true
+		run.Steps = len(solution.Steps)
+	}
+	return run
+}
+
+// Random returns count random puzzles with capacities up to maxCapacity and z
+// up to the largest capacity, the same seed always returns the same puzzles.
+func Random(count, maxCapacity int, seed int64) []Puzzle {
+	rng := rand.New(rand.NewSource(seed))
+	puzzles := make([]Puzzle, count)
+	for i := range puzzles {
+		x, y := 1+rng.Intn(maxCapacity), 1+rng.Intn(maxCapacity)
+		z := 1 + rng.Intn(maxInt(x, y))
+		puzzles[i] = Puzzle{X: x, Y: y, Z: z}
+	}
+	return puzzles
+}
+
+func maxInt(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
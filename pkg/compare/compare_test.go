@@ -0,0 +1,139 @@
+package compare_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/compare"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/iterative"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+func TestCompare(t *testing.T) {
+
+	// never claims every puzzle has no solution, and fails on z = 1.
+	never := app.SolverFun(func(state models.State, z int) (models.Solution, error) {
+		if z == 1 {
+			return models.Solution{}, errors.New("broken")
+		}
+		return models.Solution{}, models.ErrNoSolution
+	})
+	report, err := compare.Compare(compare.Configuration{
+		Solvers: []compare.Solver{
+			{Name: "iterative", Solver: app.SolverFun(iterative.Solve)},
+			{Name: "bfs", Solver: app.SolverFun(bfs.Solve)},
+			{Name: "never", Solver: never},
+		},
+		Puzzles: []compare.Puzzle{{X: 5, Y: 3, Z: 4}, {X: 6, Y: 4, Z: 3}, {X: 3, Y: 2, Z: 1}},
+		Repeat:  3,
+	})
+	require.NoError(t, err)
+	require.Len(t, report.Comparisons, 3)
+
+	solved := report.Comparisons[0]
+	assert.Equal(t, compare.Puzzle{X: 5, Y: 3, Z: 4}, solved.Puzzle)
+	assert.True(t, solved.Disagreement)
+	require.Len(t, solved.Runs, 3)
+	for _, run := range solved.Runs[:2] {
+		assert.True(t, run.Solvable)
+		assert.Equal(t, 6, run.Steps)
+		assert.Positive(t, run.Duration)
+		assert.Positive(t, run.Allocs)
+	}
+	assert.Equal(t, "never", solved.Runs[2].Solver)
+	assert.False(t, solved.Runs[2].Solvable)
+
+	assert.False(t, report.Comparisons[1].Disagreement, "no solver finds a solution")
+	failed := report.Comparisons[2]
+	assert.False(t, failed.Disagreement, "failed runs are left out")
+	assert.Equal(t, "broken", failed.Runs[2].Error)
+
+	assert.Equal(t, 1, report.Disagreements)
+	require.Len(t, report.Totals, 3)
+	assert.Equal(t, "bfs", report.Totals[1].Solver)
+	assert.Equal(t, 2, report.Totals[1].Solved)
+	assert.Equal(t, 8, report.Totals[1].Steps)
+	assert.Equal(t, compare.Total{Solver: "never", Failed: 1,
+		Duration: report.Totals[2].Duration, Allocs: report.Totals[2].Allocs, Bytes: report.Totals[2].Bytes},
+		report.Totals[2])
+}
+
+func TestInvalid(t *testing.T) {
+
+	_, err := compare.Compare(compare.Configuration{Puzzles: []compare.Puzzle{{X: 5, Y: 3, Z: 4}}})
+	assert.Error(t, err, "there must be solvers")
+
+	_, err = compare.Compare(compare.Configuration{Solvers: []compare.Solver{{Name: "nil"}}})
+	assert.Error(t, err, "solvers cannot be nil")
+}
+
+func TestRandom(t *testing.T) {
+
+	puzzles := compare.Random(100, 10, 1)
+	require.Len(t, puzzles, 100)
+	for _, p := range puzzles {
+		assert.True(t, p.X >= 1 && p.X <= 10 && p.Y >= 1 && p.Y <= 10, "%+v", p)
+		assert.True(t, p.Z >= 1 && (p.Z <= p.X || p.Z <= p.Y), "%+v", p)
+	}
+	assert.Equal(t, puzzles, compare.Random(100, 10, 1))
+	assert.NotEqual(t, puzzles, compare.Random(100, 10, 2))
+}
+
+func TestWrite(t *testing.T) {
+
+	report := compare.Report{
+		Comparisons: []compare.Comparison{
+			{
+				Puzzle: compare.Puzzle{X: 5, Y: 3, Z: 4},
+				Runs: []compare.Run{
+					{Solver: "iterative", Solvable: true, Steps: 6, Duration: 20 * time.Microsecond, Allocs: 23, Bytes: 2520},
+					{Solver: "broken", Duration: time.Microsecond, Allocs: 1, Bytes: 16, Error: "broken"},
+				},
+			},
+			{
+				Puzzle:       compare.Puzzle{X: 6, Y: 4, Z: 2},
+				Disagreement: true,
+				Runs: []compare.Run{
+					{Solver: "iterative", Solvable: true, Steps: 4, Duration: 7 * time.Microsecond, Allocs: 26, Bytes: 3736},
+					{Solver: "broken", Duration: time.Microsecond, Allocs: 1, Bytes: 16},
+				},
+			},
+		},
+		Totals: []compare.Total{
+			{Solver: "iterative", Solved: 2, Steps: 10, Duration: 27 * time.Microsecond, Allocs: 49, Bytes: 6256},
+			{Solver: "broken", Failed: 1, Duration: time.Microsecond, Allocs: 1, Bytes: 16},
+		},
+		Disagreements: 1,
+	}
+
+	output := &bytes.Buffer{}
+	require.NoError(t, report.Write(output, compare.FormatText))
+	assert.Equal(t, ""+
+		"X  Y  Z  SOLVER     STEPS  TIME  ALLOCS  BYTES  NOTE\n"+
+		"5  3  4  iterative  6      20µs  23      2520\n"+
+		"5  3  4  broken            1µs   1       16     error: broken\n"+
+		"6  4  2  iterative  4      7µs   26      3736   disagreement\n"+
+		"6  4  2  broken     -      1µs   1       16     disagreement\n"+
+		"\n"+
+		"SOLVER     SOLVED  FAILED  STEPS  TIME  ALLOCS  BYTES\n"+
+		"iterative  2       0       10     27µs  49      6256\n"+
+		"broken     0       1       0      1µs   1       16\n"+
+		"\n"+
+		"1 of 2 puzzles with disagreements\n", output.String())
+
+	output.Reset()
+	require.NoError(t, report.Write(output, compare.FormatJSON))
+	var decoded compare.Report
+	require.NoError(t, json.Unmarshal(output.Bytes(), &decoded))
+	assert.Equal(t, report, decoded)
+
+	assert.Error(t, report.Write(output, "yaml"))
+}
@@ -0,0 +1,78 @@
+package compare
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Format is the format of a written Report.
+type Format string
+
+const (
+	// FormatText writes a table with a row for each solver on each puzzle,
+	// followed by a table with the totals of each solver.
+	FormatText Format = "text"
+	// FormatJSON writes the Report as a single JSON object.
+	FormatJSON Format = "json"
+)
+
+// Formats lists every format.
+var Formats = []Format{FormatText, FormatJSON}
+
+// Write writes the report in the format.
+func (r Report) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(r)
+	case FormatText:
+		return r.writeText(w)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func (r Report) writeText(w io.Writer) error {
+	// Rows are padded into a buffer first so the padding after an empty
+	// last cell can be trimmed.
+	buf := &bytes.Buffer{}
+	tw := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "X\tY\tZ\tSOLVER\tSTEPS\tTIME\tALLOCS\tBYTES\tNOTE")
+	for _, c := range r.Comparisons {
+		for _, run := range c.Runs {
+			steps, note := "-", ""
+			if run.Solvable {
+				steps = strconv.Itoa(run.Steps)
+			}
+			if c.Disagreement {
+				note = "disagreement"
+			}
+			if run.Error != "" {
+				steps, note = "", "error: "+run.Error
+			}
+			fmt.Fprintf(tw, "%d\t%d\t%d\t%s\t%s\t%s\t%d\t%d\t%s\n",
+				c.X, c.Y, c.Z, run.Solver, steps, run.Duration, run.Allocs, run.Bytes, note)
+		}
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "SOLVER\tSOLVED\tFAILED\tSTEPS\tTIME\tALLOCS\tBYTES")
+	for _, t := range r.Totals {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%d\t%d\n",
+			t.Solver, t.Solved, t.Failed, t.Steps, t.Duration, t.Allocs, t.Bytes)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if _, err := fmt.Fprintln(w, strings.TrimRight(line, " ")); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "\n%d of %d puzzles with disagreements\n", r.Disagreements, len(r.Comparisons))
+	return err
+}
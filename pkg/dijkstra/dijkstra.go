@@ -0,0 +1,183 @@
+// Package dijkstra implements a solution to the water jug puzzle with the
+// least total cost, when actions have different costs.
+//
+// It searches the same graph as package bfs, but every edge weighs as much as
+// its action costs, see models.Costs. Nodes are visited in order of the cost
+// of reaching them, so the first node meeting the winning condition is reached
+// with the least possible cost.
+//
+// Let's take x = 5, y = 3, z = 4 as an example, with filling costing 3 and
+// everything else 1.
+//
+// Fill X -> Transfer to Y -> Empty Y -> Transfer to Y -> Fill X -> Transfer to Y
+//
+// takes 6 steps, filling twice, and costs 10.
+//
+// Fill Y -> Transfer to X -> Fill Y -> Transfer to X -> Empty X -> Transfer to X -> Fill Y -> Transfer to X
+//
+// takes 8 steps, filling three times, and costs 14. With filling costing 1 and
+// transferring 0 they would cost 3 and 4, the first one is the cheapest either
+// way.
+//
+// Solutions with the same cost are broken by the least amount of steps.
+package dijkstra
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// Solver finds the cheapest solution to the puzzle given the Costs of each
+// action. It is an app.ContextSolver and an app.ContextGoalSolver.
+type Solver struct {
+	Costs models.Costs
+}
+
+// New returns a Solver for the costs, which must not be negative.
+func New(costs models.Costs) (Solver, error) {
+	if err := costs.Validate(); err != nil {
+		return Solver{}, err
+	}
+	return Solver{Costs: costs}, nil
+}
+
+// Solve solves the water jugs riddle with the least total cost, starting from
+// the amounts in the baseState.
+//
+// An error ErrNoSolution is returned if no solution exists, a
+// *models.AmountError is returned if the starting amounts are invalid.
+func (s Solver) Solve(baseState models.State, z int) (models.Solution, error) {
+	return s.SolveContext(context.Background(), baseState, z)
+}
+
+// SolveContext is Solve, but it stops with ctx.Err() once ctx is done.
+func (s Solver) SolveContext(ctx context.Context, baseState models.State, z int) (models.Solution, error) {
+
+	x := baseState.X.Capacity
+	y := baseState.Y.Capacity
+	if z > x && z > y {
+		return models.Solution{}, errors.New("z must be smaller than either x or y")
+	}
+	if z < 0 {
+		return models.Solution{}, errors.New("z must be zero or greater")
+	}
+
+	return s.SolveGoalContext(ctx, baseState, models.AnyJug{Z: z})
+}
+
+// SolveGoal solves the water jugs riddle with the least total cost, starting
+// from the amounts in the baseState, until the goal is reached.
+//
+// An error ErrNoSolution is returned if no solution exists, a
+// *models.AmountError is returned if the starting amounts are invalid.
+func (s Solver) SolveGoal(baseState models.State, goal models.Goal) (models.Solution, error) {
+	return s.SolveGoalContext(context.Background(), baseState, goal)
+}
+
+// SolveGoalContext is SolveGoal, but it stops with ctx.Err() once ctx is done,
+// which is checked before visiting every node.
+func (s Solver) SolveGoalContext(ctx context.Context, baseState models.State, goal models.Goal) (models.Solution, error) {
+
+	if err := s.Costs.Validate(); err != nil {
+		return models.Solution{}, err
+	}
+	if baseState.Y.Capacity <= 0 || baseState.X.Capacity <= 0 {
+		return models.Solution{}, errors.New("both x and y must be positive")
+	}
+	if err := baseState.CheckAmounts(); err != nil {
+		return models.Solution{}, err
+	}
+	if err := goal.Validate(baseState.Jugs()); err != nil {
+		return models.Solution{}, err
+	}
+
+	start := baseState
+	best := map[models.State]node{start: {state: start}}
+	visited := map[models.State]bool{}
+	queue := &priorityQueue{{state: start}}
+	for queue.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return models.Solution{}, err
+		}
+		current := heap.Pop(queue).(node)
+		if visited[current.state] {
+			continue
+		}
+		visited[current.state] = true
+
+		if goal.Reached(current.state.Jugs()) {
+			return path(start, current.state, best), nil
+		}
+
+		for _, action := range models.Actions {
+			next := node{
+				state:  current.state.Apply(action),
+				from:   current.state,
+				action: action,
+				cost:   current.cost + s.Costs[action],
+				steps:  current.steps + 1,
+			}
+			if visited[next.state] {
+				continue
+			}
+			if known, ok := best[next.state]; ok && !next.cheaper(known) {
+				continue
+			}
+			best[next.state] = next
+			heap.Push(queue, next)
+		}
+	}
+
+	return models.Solution{}, models.ErrNoSolution
+}
+
+// node is a state along with the cheapest known way of reaching it.
+type node struct {
+	state  models.State
+	from   models.State
+	action models.Action
+	cost   int
+	steps  int
+}
+
+func (n node) cheaper(other node) bool {
+	if n.cost != other.cost {
+		return n.cost < other.cost
+	}
+	return n.steps < other.steps
+}
+
+// priorityQueue is a min-heap of nodes, see container/heap.
+type priorityQueue []node
+
+func (q priorityQueue) Len() int           { return len(q) }
+func (q priorityQueue) Less(i, j int) bool { return q[i].cheaper(q[j]) }
+func (q priorityQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *priorityQueue) Push(x any)        { *q = append(*q, x.(node)) }
+func (q *priorityQueue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+// path walks back from the end node to the start node, building the Solution
+// in the right order.
+func path(start, end models.State, best map[models.State]node) models.Solution {
+
+	var steps []models.Step
+	for current := end; current != start; current = best[current].from {
+		steps = append(steps, models.Step{
+			State:  current,
+			Action: best[current].action,
+		})
+	}
+
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return models.Solution{Steps: steps}
+}
@@ -0,0 +1,175 @@
+package dijkstra_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/dijkstra"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/paths"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvertest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoSolution(t *testing.T) {
+
+	_, err := dijkstra.Solver{Costs: models.UnitCosts}.Solve(solvertest.NewState(9, 3), 4)
+
+	assert.ErrorIs(t, err, models.ErrNoSolution)
+}
+
+func TestInvalid(t *testing.T) {
+
+	solver := dijkstra.Solver{Costs: models.UnitCosts}
+
+	t.Run("x should be positive", func(t *testing.T) {
+		_, err := solver.Solve(solvertest.NewState(-5, 3), 4)
+		assert.Error(t, err)
+	})
+
+	t.Run("z should be lower than either x or y", func(t *testing.T) {
+		_, err := solver.Solve(solvertest.NewState(5, 3), 10)
+		assert.Error(t, err)
+	})
+
+	t.Run("amounts should be between 0 and the capacity", func(t *testing.T) {
+		state := solvertest.NewState(5, 3)
+		state.Y.Amount = 4
+		_, err := solver.Solve(state, 1)
+
+		var amountErr *models.AmountError
+		assert.ErrorAs(t, err, &amountErr)
+	})
+
+	t.Run("costs should not be negative", func(t *testing.T) {
+		_, err := dijkstra.New(models.Costs{models.ActionEmptyX: -1})
+		assert.Error(t, err)
+
+		_, err = dijkstra.Solver{Costs: models.Costs{models.ActionEmptyX: -1}}.Solve(solvertest.NewState(5, 3), 4)
+		assert.Error(t, err)
+	})
+}
+
+func TestSolutions(t *testing.T) {
+
+	t.Run("expensive filling should prefer fewer fills", func(t *testing.T) {
+		costs, err := models.ParseCosts("fill=3")
+		require.NoError(t, err)
+
+		solution, err := dijkstra.Solver{Costs: costs}.Solve(solvertest.NewState(5, 3), 4)
+		require.NoError(t, err)
+
+		assert.Equal(t, 10, costs.Total(solution.Steps))
+		assert.Len(t, solution.Steps, 6)
+	})
+
+	t.Run("free actions should not loop", func(t *testing.T) {
+		costs := models.Costs{models.ActionFillX: 1, models.ActionFillY: 1}
+
+		solution, err := dijkstra.Solver{Costs: costs}.Solve(solvertest.NewState(5, 3), 4)
+		require.NoError(t, err)
+		require.NoError(t, models.Validate(solvertest.NewState(5, 3), models.AnyJug{Z: 4}, solution))
+
+		assert.Equal(t, 2, costs.Total(solution.Steps))
+	})
+
+	t.Run("ties should be broken by the amount of steps", func(t *testing.T) {
+		solution, err := dijkstra.Solver{Costs: models.Costs{}}.Solve(solvertest.NewState(5, 4), 5)
+		require.NoError(t, err)
+
+		assert.Len(t, solution.Steps, 1)
+	})
+
+	t.Run("z already measured should take 0 steps", func(t *testing.T) {
+		state := solvertest.NewState(5, 3)
+		state.Y.Amount = 2
+
+		s, err := dijkstra.Solver{Costs: models.UnitCosts}.Solve(state, 2)
+		require.NoError(t, err)
+		assert.Len(t, s.Steps, 0)
+	})
+}
+
+func TestSameAsBFS(t *testing.T) {
+
+	solver := dijkstra.Solver{Costs: models.UnitCosts}
+	for x := 1; x <= 10; x++ {
+		for y := 1; y <= 10; y++ {
+			for z := 0; z <= x+y; z++ {
+				goal := models.Sum{Z: z}
+				expected, expectedErr := bfs.SolveGoal(solvertest.NewState(x, y), goal)
+				solution, err := solver.SolveGoal(solvertest.NewState(x, y), goal)
+				if expectedErr != nil {
+					assert.ErrorIs(t, err, models.ErrNoSolution)
+					continue
+				}
+				require.NoError(t, err)
+				assert.Len(t, solution.Steps, len(expected.Steps), "x=%d, y=%d, z=%d", x, y, z)
+			}
+		}
+	}
+}
+
+// TestCheapest compares the cost of the solutions with every other solution
+// for small puzzles.
+func TestCheapest(t *testing.T) {
+
+	costs := models.Costs{
+		models.ActionFillX:     4,
+		models.ActionFillY:     1,
+		models.ActionEmptyX:    0,
+		models.ActionEmptyY:    2,
+		models.ActionTransferX: 3,
+		models.ActionTransferY: 1,
+	}
+	solver := dijkstra.Solver{Costs: costs}
+	for x := 1; x <= 5; x++ {
+		for y := 1; y <= 5; y++ {
+			t.Run(fmt.Sprintf("x=%d, y=%d", x, y), func(t *testing.T) {
+				for z := 0; z <= x || z <= y; z++ {
+					goal := models.AnyJug{Z: z}
+					solution, err := solver.SolveGoal(solvertest.NewState(x, y), goal)
+					if errors.Is(err, models.ErrNoSolution) {
+						continue
+					}
+					require.NoError(t, err)
+					require.NoError(t, models.Validate(solvertest.NewState(x, y), goal, solution))
+
+					it, err := paths.Enumerate(solvertest.NewState(x, y), goal, 0)
+					require.NoError(t, err)
+					for it.Next() {
+						assert.LessOrEqual(t, costs.Total(solution.Steps), costs.Total(it.Solution().Steps))
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := dijkstra.Solver{Costs: models.UnitCosts}.SolveContext(ctx, solvertest.NewState(5, 3), 4)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestConformance(t *testing.T) {
+	solvertest.TestSolver(t, dijkstra.Solver{Costs: models.UnitCosts})
+}
+
+func FuzzSolve(f *testing.F) {
+	solvertest.FuzzSolver(f, dijkstra.Solver{Costs: models.UnitCosts})
+}
+
+var (
+	_ app.ContextSolver     = dijkstra.Solver{}
+	_ app.ContextGoalSolver = dijkstra.Solver{}
+)
@@ -0,0 +1,16 @@
+package dijkstra
+
+import (
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvers"
+)
+
+func init() {
+	solver := Solver{Costs: models.UnitCosts}
+	solvers.Register(solvers.Registration{
+		Name:        "dijkstra",
+		Description: "finds the cheapest solution to any goal, every action costing 1",
+		Solve:       solver.SolveContext,
+		SolveGoal:   solver.SolveGoalContext,
+	})
+}
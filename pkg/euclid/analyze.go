@@ -0,0 +1,107 @@
+package euclid
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Amounts are the amounts of water a jug can hold, every multiple of Step from
+// 0 up to Capacity.
+type Amounts struct {
+	Step     int `json:"step"`
+	Capacity int `json:"capacity"`
+}
+
+// Count returns how many amounts there are.
+func (a Amounts) Count() int {
+	return a.Capacity/a.Step + 1
+}
+
+// String lists the amounts, eliding the middle ones if there are many.
+func (a Amounts) String() string {
+	var amounts []string
+	if a.Count() <= 6 {
+		for amount := 0; amount <= a.Capacity; amount += a.Step {
+			amounts = append(amounts, strconv.Itoa(amount))
+		}
+		return strings.Join(amounts, ", ")
+	}
+	return fmt.Sprintf("0, %d, %d, ..., %d, %d (%d amounts)",
+		a.Step, 2*a.Step, a.Capacity-a.Step, a.Capacity, a.Count())
+}
+
+// Analysis explains whether z can be measured with an x and a y jugs, which
+// start empty, and how many steps it takes.
+type Analysis struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	Z int `json:"z"`
+	// GCD is gcd(x, y), every amount measured is a multiple of it.
+	GCD int `json:"gcd"`
+	// Multiple is whether z is a multiple of GCD.
+	Multiple bool `json:"multiple"`
+	// ExceedsCapacities is whether z is greater than both x and y.
+	ExceedsCapacities bool `json:"exceeds_capacities"`
+	// ReachableX and ReachableY are the amounts each jug can hold.
+	ReachableX Amounts `json:"reachable_x"`
+	ReachableY Amounts `json:"reachable_y"`
+	Solvable   bool    `json:"solvable"`
+	// Counts are the steps each strategy takes, nil if there is no solution.
+	Counts *Counts `json:"counts"`
+}
+
+// Reason explains why there is no solution, it is empty if there is one.
+func (a Analysis) Reason() string {
+	switch {
+	case a.Solvable:
+		return ""
+	case a.ExceedsCapacities:
+		return fmt.Sprintf("%d exceeds both capacities", a.Z)
+	default:
+		return fmt.Sprintf("%d is not a multiple of gcd(%d, %d) = %d", a.Z, a.X, a.Y, a.GCD)
+	}
+}
+
+// Analyze explains whether z can be measured with an x and a y jugs, which
+// start empty, in constant time. It never builds the Solution, see Count.
+//
+// An error is only returned if the parameters are not valid, as in negative
+// capacities, or if a step count overflows, see ErrOverflow.
+func Analyze(x, y, z int) (Analysis, error) {
+
+	if y <= 0 || x <= 0 {
+		return Analysis{}, errors.New("both x and y must be positive")
+	}
+	if z < 0 {
+		return Analysis{}, errors.New("z must be zero or greater")
+	}
+
+	g := gcd(x, y)
+	a := Analysis{
+		X:                 x,
+		Y:                 y,
+		Z:                 z,
+		GCD:               g,
+		Multiple:          z%g == 0,
+		ExceedsCapacities: z > x && z > y,
+		ReachableX:        Amounts{Step: g, Capacity: x},
+		ReachableY:        Amounts{Step: g, Capacity: y},
+	}
+	a.Solvable = a.Multiple && !a.ExceedsCapacities
+	if !a.Solvable {
+		return a, nil
+	}
+
+	xToY, err := strategySteps(x, y, z)
+	if err != nil {
+		return Analysis{}, err
+	}
+	yToX, err := strategySteps(y, x, z)
+	if err != nil {
+		return Analysis{}, err
+	}
+	a.Counts = &Counts{XToY: xToY, YToX: yToX}
+	return a, nil
+}
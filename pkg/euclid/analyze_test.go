@@ -0,0 +1,100 @@
+package euclid_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/euclid"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/graph"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvertest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze(t *testing.T) {
+
+	t.Run("solvable", func(t *testing.T) {
+		a, err := euclid.Analyze(5, 3, 4)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, a.GCD)
+		assert.True(t, a.Multiple)
+		assert.False(t, a.ExceedsCapacities)
+		assert.True(t, a.Solvable)
+		assert.Empty(t, a.Reason())
+		assert.Equal(t, &euclid.Counts{XToY: 6, YToX: 8}, a.Counts)
+	})
+
+	t.Run("not a multiple of the gcd", func(t *testing.T) {
+		a, err := euclid.Analyze(6, 9, 4)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, a.GCD)
+		assert.False(t, a.Solvable)
+		assert.Nil(t, a.Counts)
+		assert.Equal(t, "4 is not a multiple of gcd(6, 9) = 3", a.Reason())
+		assert.Equal(t, "0, 3, 6", a.ReachableX.String())
+		assert.Equal(t, "0, 3, 6, 9", a.ReachableY.String())
+	})
+
+	t.Run("exceeding the capacities", func(t *testing.T) {
+		a, err := euclid.Analyze(6, 9, 12)
+		require.NoError(t, err)
+
+		assert.True(t, a.Multiple)
+		assert.True(t, a.ExceedsCapacities)
+		assert.Equal(t, "12 exceeds both capacities", a.Reason())
+	})
+
+	t.Run("huge capacities", func(t *testing.T) {
+		a, err := euclid.Analyze(1_000_000_007, 1_000_000_009, 2)
+		require.NoError(t, err)
+
+		assert.True(t, a.Solvable)
+		assert.Equal(t, "0, 1, 2, ..., 1000000006, 1000000007 (1000000008 amounts)", a.ReachableX.String())
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		_, err := euclid.Analyze(0, 3, 1)
+		assert.Error(t, err)
+
+		_, err = euclid.Analyze(5, 3, -1)
+		assert.Error(t, err)
+	})
+}
+
+// TestAnalyzeSameAsGraph checks the reachable amounts and solvability against
+// every state reachable from empty jugs.
+func TestAnalyzeSameAsGraph(t *testing.T) {
+
+	for x := 1; x <= 10; x++ {
+		for y := 1; y <= 10; y++ {
+			t.Run(fmt.Sprintf("x=%d, y=%d", x, y), func(t *testing.T) {
+				g, err := graph.Build(solvertest.NewState(x, y))
+				require.NoError(t, err)
+				inX, inY := map[int]bool{}, map[int]bool{}
+				for _, node := range g.Nodes {
+					inX[node.X.Amount] = true
+					inY[node.Y.Amount] = true
+				}
+
+				for z := 0; z <= x+y; z++ {
+					a, err := euclid.Analyze(x, y, z)
+					require.NoError(t, err)
+					assert.Equal(t, inX[z] || inY[z], a.Solvable, "z=%d", z)
+
+					if a.Solvable {
+						counts, err := euclid.Count(solvertest.NewState(x, y), z)
+						require.NoError(t, err)
+						assert.Equal(t, counts, *a.Counts)
+					}
+				}
+				a, err := euclid.Analyze(x, y, 0)
+				require.NoError(t, err)
+				assert.Len(t, inX, a.ReachableX.Count())
+				assert.Len(t, inY, a.ReachableY.Count())
+			})
+		}
+	}
+}
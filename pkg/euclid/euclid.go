@@ -0,0 +1,261 @@
+// Package euclid solves the water jug puzzle in closed form, without
+// simulating every pour.
+//
+// Consider the strategy used by the iterative package: fill the "from" jug,
+// transfer to the "to" jug, empty "to" whenever it is full and fill "from"
+// whenever it is empty. Let P be the total amount of water transferred so far,
+// every transfer stops when "from" is empty, that is when P is a multiple of
+// its capacity a, or when "to" is full, that is when P is a multiple of its
+// capacity b.
+//
+// So every transfer ends on a breakpoint, a multiple of either a or b, and
+// every breakpoint but the last one is followed by exactly one fill or one
+// empty. Taking into account the very first fill, a strategy that wins on
+// its n-th breakpoint takes 2n steps.
+//
+// The "to" jug measures z after a transfer ending on P = k*a such that
+// k*a = z (mod b), while the "from" jug measures z after a transfer ending on
+// P = j*b such that j*b = -z (mod a). Both equations have solutions only if
+// gcd(a, b) divides z, and the smallest k and j are found with the modular
+// inverses given by the extended Euclidean algorithm.
+//
+// The amount of breakpoints up to k*a is k multiples of a plus floor(k*a/b)
+// multiples of b, the same reasoning applies to j*b.
+//
+// This means solvability and step counts are known in constant time, even for
+// capacities where simulating would take forever. The Steps themselves are
+// only built when the Solution is requested.
+package euclid
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// ErrOverflow indicates that the step count does not fit in an int.
+var ErrOverflow = errors.New("step count overflows int")
+
+// Counts are the amount of steps required by each strategy.
+type Counts struct {
+	// XToY is the amount of steps when filling X and transferring to Y.
+	XToY int `json:"x_to_y"`
+	// YToX is the amount of steps when filling Y and transferring to X.
+	YToX int `json:"y_to_x"`
+}
+
+// Min returns the amount of steps of the shortest strategy.
+func (c Counts) Min() int {
+	if c.XToY < c.YToX {
+		return c.XToY
+	}
+	return c.YToX
+}
+
+// Solvable decides whether z can be measured with an x and a y jugs.
+func Solvable(x, y, z int) bool {
+	if x <= 0 || y <= 0 || z < 0 || (z > x && z > y) {
+		return false
+	}
+	return z%gcd(x, y) == 0
+}
+
+// Count calculates the amount of steps each strategy requires without
+// building them.
+//
+// The closed form only holds for empty jugs, models.ErrUnsupported is returned
+// otherwise. An error ErrNoSolution is returned if no solution exists.
+func Count(baseState models.State, z int) (Counts, error) {
+
+	x := baseState.X.Capacity
+	y := baseState.Y.Capacity
+	if z > x && z > y {
+		return Counts{}, errors.New("z must be smaller than either x or y")
+	}
+	if z < 0 {
+		return Counts{}, errors.New("z must be zero or greater")
+	}
+	if y <= 0 || x <= 0 {
+		return Counts{}, errors.New("both x and y must be positive")
+	}
+
+	if baseState.X.Amount != 0 || baseState.Y.Amount != 0 {
+		return Counts{}, fmt.Errorf("%w: jugs must start empty", models.ErrUnsupported)
+	}
+
+	if !Solvable(x, y, z) {
+		return Counts{}, models.ErrNoSolution
+	}
+
+	xToY, err := strategySteps(x, y, z)
+	if err != nil {
+		return Counts{}, err
+	}
+	yToX, err := strategySteps(y, x, z)
+	if err != nil {
+		return Counts{}, err
+	}
+	return Counts{XToY: xToY, YToX: yToX}, nil
+}
+
+// Solve solves the water jugs riddle, returning the same Solution as the
+// iterative package.
+//
+// An error ErrNoSolution is returned if no solution exists.
+func Solve(baseState models.State, z int) (models.Solution, error) {
+
+	counts, err := Count(baseState, z)
+	if err != nil {
+		return models.Solution{}, err
+	}
+
+	start := models.State{
+		X: models.Jug{Capacity: baseState.X.Capacity},
+		Y: models.Jug{Capacity: baseState.Y.Capacity},
+	}
+	if counts.XToY < counts.YToX {
+		return build(start, counts.XToY, xToY), nil
+	}
+	return build(start, counts.YToX, yToX), nil
+}
+
+// strategy indicates the actions taken when filling one jug and transferring
+// to the other one.
+type strategy struct {
+	fill, empty, transfer models.Action
+	from, to              func(s models.State) models.Jug
+}
+
+var (
+	xToY = strategy{
+		fill:     models.ActionFillX,
+		empty:    models.ActionEmptyY,
+		transfer: models.ActionTransferY,
+		from:     func(s models.State) models.Jug { return s.X },
+		to:       func(s models.State) models.Jug { return s.Y },
+	}
+	yToX = strategy{
+		fill:     models.ActionFillY,
+		empty:    models.ActionEmptyX,
+		transfer: models.ActionTransferX,
+		from:     func(s models.State) models.Jug { return s.Y },
+		to:       func(s models.State) models.Jug { return s.X },
+	}
+)
+
+// build generates the Steps for a strategy, which is known to win in exactly
+// count steps.
+func build(state models.State, count int, st strategy) models.Solution {
+
+	if count == 0 {
+		return models.Solution{}
+	}
+
+	steps := make([]models.Step, 0, count)
+	apply := func(a models.Action) {
+		state = state.Apply(a)
+		steps = append(steps, models.Step{State: state, Action: a})
+	}
+
+	apply(st.fill)
+	for len(steps) < count {
+		if st.to(state).Amount == st.to(state).Capacity {
+			apply(st.empty)
+		}
+		if st.from(state).Amount == 0 {
+			apply(st.fill)
+		}
+		apply(st.transfer)
+	}
+	return models.Solution{Steps: steps}
+}
+
+// strategySteps calculates the amount of steps required to measure z by
+// filling the a jug and transferring to the b jug.
+//
+// z must be multiple of gcd(a, b) and not greater than both a and b.
+func strategySteps(a, b, z int) (int, error) {
+
+	if z == 0 {
+		return 0, nil
+	}
+	// The very first fill wins.
+	if z == a {
+		return 1, nil
+	}
+
+	g := gcd(a, b)
+	breakpoints := uint64(math.MaxUint64)
+
+	switch {
+	case z == b:
+		// b is the first multiple of b, we count the multiples of a up to it.
+		breakpoints = uint64((b + a - 1) / a)
+	case z < b:
+		// k*a = z (mod b)
+		m := uint64(b / g)
+		k := mulMod(uint64(z/g), inverse(uint64(a/g), m), m)
+		hi, lo := bits.Mul64(k, uint64(a))
+		q, _ := bits.Div64(hi, lo, uint64(b))
+		breakpoints = k + q
+	}
+
+	if z < a {
+		// j*b = -z (mod a)
+		m := uint64(a / g)
+		j := mulMod(uint64((a-z)/g), inverse(uint64(b/g), m), m)
+		hi, lo := bits.Mul64(j, uint64(b))
+		p, _ := bits.Div64(hi, lo, uint64(a))
+		if j+p < breakpoints {
+			breakpoints = j + p
+		}
+	}
+
+	if breakpoints > math.MaxInt/2 {
+		return 0, ErrOverflow
+	}
+	return int(2 * breakpoints), nil
+}
+
+// inverse returns the modular inverse of a modulo m, using the extended
+// Euclidean algorithm.
+//
+// a and m must be coprime.
+func inverse(a, m uint64) uint64 {
+	if m == 1 {
+		return 0
+	}
+
+	// The invariant is r_i = s_i * a (mod m), the coefficients are kept
+	// modulo m to avoid negative numbers.
+	r0, r1 := m, a%m
+	s0, s1 := uint64(0), uint64(1)
+	for r1 != 0 {
+		q := r0 / r1
+		r0, r1 = r1, r0-q*r1
+		s0, s1 = s1, subMod(s0, mulMod(q, s1, m), m)
+	}
+	return s0
+}
+
+func mulMod(a, b, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return bits.Rem64(hi, lo, m)
+}
+
+func subMod(a, b, m uint64) uint64 {
+	if a >= b {
+		return a - b
+	}
+	return m - (b - a)
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,117 @@
+package euclid_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/euclid"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/iterative"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvertest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoSolution(t *testing.T) {
+
+	_, err := euclid.Count(solvertest.NewState(9, 3), 4)
+	assert.ErrorIs(t, err, models.ErrNoSolution)
+
+	_, err = euclid.Solve(solvertest.NewState(9, 3), 4)
+	assert.ErrorIs(t, err, models.ErrNoSolution)
+}
+
+func TestInvalid(t *testing.T) {
+
+	t.Run("x should be positive", func(t *testing.T) {
+		_, err := euclid.Count(solvertest.NewState(-5, 3), 4)
+		assert.Error(t, err)
+	})
+
+	t.Run("y should be positive", func(t *testing.T) {
+		_, err := euclid.Count(solvertest.NewState(5, -3), 4)
+		assert.Error(t, err)
+	})
+
+	t.Run("z should be zero or greater", func(t *testing.T) {
+		_, err := euclid.Count(solvertest.NewState(5, 3), -4)
+		assert.Error(t, err)
+	})
+
+	t.Run("z should be lower than either x or y", func(t *testing.T) {
+		_, err := euclid.Count(solvertest.NewState(5, 3), 10)
+		assert.Error(t, err)
+	})
+
+	t.Run("jugs should start empty", func(t *testing.T) {
+		state := solvertest.NewState(5, 3)
+		state.X.Amount = 1
+		_, err := euclid.Count(state, 2)
+		assert.ErrorIs(t, err, models.ErrUnsupported)
+	})
+}
+
+func TestCount(t *testing.T) {
+
+	t.Run("x=5, y=3, z=4", func(t *testing.T) {
+		counts, err := euclid.Count(solvertest.NewState(5, 3), 4)
+		require.NoError(t, err)
+		assert.Equal(t, euclid.Counts{XToY: 6, YToX: 8}, counts)
+	})
+
+	t.Run("huge capacities", func(t *testing.T) {
+		counts, err := euclid.Count(solvertest.NewState(1_000_000_007, 999_999_937), 1)
+		require.NoError(t, err)
+		assert.Equal(t, euclid.Counts{XToY: 3_257_142_764, YToX: 742_857_120}, counts)
+	})
+
+	t.Run("big capacities are the same as iterative", func(t *testing.T) {
+		solution, err := iterative.Solve(solvertest.NewState(10_007, 9_973), 1)
+		require.NoError(t, err)
+
+		counts, err := euclid.Count(solvertest.NewState(10_007, 9_973), 1)
+		require.NoError(t, err)
+		assert.Equal(t, len(solution.Steps), counts.Min())
+	})
+
+	t.Run("huge capacities without solution", func(t *testing.T) {
+		_, err := euclid.Count(solvertest.NewState(4_000_000_000, 6_000_000_000), 1_000_000_001)
+		assert.ErrorIs(t, err, models.ErrNoSolution)
+	})
+}
+
+// TestSameAsIterative cross-checks both solvers, the closed form must build
+// exactly the same Solution the iterative simulation does.
+func TestSameAsIterative(t *testing.T) {
+
+	for x := 1; x <= 20; x++ {
+		for y := 1; y <= 20; y++ {
+			for z := 0; z <= x || z <= y; z++ {
+				t.Run(fmt.Sprintf("x=%d, y=%d, z=%d", x, y, z), func(t *testing.T) {
+					expected, expectedErr := iterative.Solve(solvertest.NewState(x, y), z)
+					solution, err := euclid.Solve(solvertest.NewState(x, y), z)
+					counts, countErr := euclid.Count(solvertest.NewState(x, y), z)
+
+					assert.Equal(t, expectedErr, err)
+					assert.Equal(t, expectedErr, countErr)
+					assert.Equal(t, expectedErr == nil, euclid.Solvable(x, y, z))
+					assert.Equal(t, expected, solution)
+					if expectedErr == nil {
+						assert.Equal(t, len(expected.Steps), counts.Min())
+						assert.NoError(t, models.Validate(solvertest.NewState(x, y), models.AnyJug{Z: z}, solution))
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestConformance(t *testing.T) {
+	solvertest.TestSolver(t, app.SolverFun(euclid.Solve))
+}
+
+func FuzzSolve(f *testing.F) {
+	solvertest.FuzzSolver(f, app.SolverFun(euclid.Solve))
+}
@@ -0,0 +1,26 @@
+package euclid
+
+import (
+	"context"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvers"
+)
+
+func init() {
+	solvers.Register(solvers.Registration{
+		Name:        "euclid",
+		Description: "counts the steps of the iterative solution in closed form, only from empty jugs",
+		Solve:       solveContext,
+		EmptyOnly:   true,
+	})
+}
+
+// solveContext is Solve, counting takes no time so ctx is only checked before
+// building the steps.
+func solveContext(ctx context.Context, baseState models.State, z int) (models.Solution, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Solution{}, err
+	}
+	return Solve(baseState, z)
+}
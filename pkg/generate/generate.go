@@ -0,0 +1,194 @@
+// Package generate generates water jug puzzles of a given difficulty, such as
+// for quizzes.
+//
+// The difficulty of a puzzle with a solution is the amount of steps of the
+// shortest one, as found by a full search solver. The difficulty of a puzzle
+// without a solution is the amount of steps a full search takes to rule it
+// out, that is the distance to the farthest state reachable from empty jugs.
+//
+// Puzzles are picked at random until there are enough of the difficulty, the
+// same Seed always generates the same puzzles.
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// Difficulty names a range of step counts.
+type Difficulty string
+
+const (
+	// Easy puzzles take from 1 to 4 steps.
+	Easy Difficulty = "easy"
+	// Medium puzzles take from 5 to 8 steps.
+	Medium Difficulty = "medium"
+	// Hard puzzles take from 9 to 16 steps.
+	Hard Difficulty = "hard"
+)
+
+// Difficulties lists every difficulty, from the easiest.
+var Difficulties = []Difficulty{Easy, Medium, Hard}
+
+// Steps returns the range of step counts of the difficulty, ok is false if it
+// is not a known difficulty.
+func (d Difficulty) Steps() (min, max int, ok bool) {
+	switch d {
+	case Easy:
+		return 1, 4, true
+	case Medium:
+		return 5, 8, true
+	case Hard:
+		return 9, 16, true
+	}
+	return 0, 0, false
+}
+
+// DefaultMaxCapacity is the largest capacity generated if it is not
+// configured.
+const DefaultMaxCapacity = 20
+
+// attempts bounds the puzzles tried for each one generated, so impossible
+// requests end.
+const attempts = 10_000
+
+// Puzzle is a generated puzzle, the jugs start empty.
+type Puzzle struct {
+	X        int  `json:"x"`
+	Y        int  `json:"y"`
+	Z        int  `json:"z"`
+	Solvable bool `json:"solvable"`
+	// Steps is the amount of steps of the shortest solution, or the steps
+	// a full search takes to rule it out if there is none.
+	Steps int `json:"steps"`
+}
+
+// Set is a group of puzzles generated together.
+type Set struct {
+	Seed       int64      `json:"seed"`
+	Difficulty Difficulty `json:"difficulty"`
+	Solvable   bool       `json:"solvable"`
+	Puzzles    []Puzzle   `json:"puzzles"`
+}
+
+// Configuration is the base configuration for generating puzzles.
+type Configuration struct {
+	// Count is the amount of puzzles, it must be positive.
+	Count int
+	// Difficulty is the difficulty of every puzzle.
+	Difficulty Difficulty
+	// Unsolvable generates puzzles without a solution instead.
+	Unsolvable bool
+	// MaxCapacity bounds the capacities, DefaultMaxCapacity is used if it
+	// is zero.
+	MaxCapacity int
+	// Seed makes the puzzles reproducible.
+	Seed int64
+	// Solver must find the shortest solution, bfs.Solve is used if it is
+	// nil.
+	Solver app.Solver
+}
+
+// Generate generates a Set of distinct puzzles. An error is returned if not
+// enough puzzles of the difficulty are found, as when the capacities are too
+// small for hard puzzles.
+func Generate(conf Configuration) (Set, error) {
+
+	if conf.Count <= 0 {
+		return Set{}, errors.New("count must be positive")
+	}
+	min, max, ok := conf.Difficulty.Steps()
+	if !ok {
+		return Set{}, fmt.Errorf("unknown difficulty %q", conf.Difficulty)
+	}
+	maxCapacity := conf.MaxCapacity
+	if maxCapacity == 0 {
+		maxCapacity = DefaultMaxCapacity
+	}
+	if maxCapacity < 2 {
+		return Set{}, errors.New("max capacity must be at least 2")
+	}
+	solver := conf.Solver
+	if solver == nil {
+		solver = app.SolverFun(bfs.Solve)
+	}
+
+	set := Set{Seed: conf.Seed, Difficulty: conf.Difficulty, Solvable: !conf.Unsolvable}
+	rng := rand.New(rand.NewSource(conf.Seed))
+	seen := map[[3]int]bool{}
+	for tries := 0; len(set.Puzzles) < conf.Count; tries++ {
+		if tries == attempts*conf.Count {
+			return Set{}, fmt.Errorf("only %d %s puzzles found with capacities up to %d",
+				len(set.Puzzles), conf.Difficulty, maxCapacity)
+		}
+
+		x, y := 1+rng.Intn(maxCapacity), 1+rng.Intn(maxCapacity)
+		z := 1 + rng.Intn(maxInt(x, y))
+		if seen[[3]int{x, y, z}] {
+			continue
+		}
+		seen[[3]int{x, y, z}] = true
+
+		puzzle, err := rate(solver, x, y, z)
+		if err != nil {
+			return Set{}, err
+		}
+		if puzzle.Solvable == conf.Unsolvable || puzzle.Steps < min || puzzle.Steps > max {
+			continue
+		}
+		set.Puzzles = append(set.Puzzles, puzzle)
+	}
+	return set, nil
+}
+
+// rate solves the puzzle to find out its difficulty.
+func rate(solver app.Solver, x, y, z int) (Puzzle, error) {
+
+	state := models.State{
+		X: models.Jug{Capacity: x},
+		Y: models.Jug{Capacity: y},
+	}
+	solution, err := solver.Solve(state, z)
+	if errors.Is(err, models.ErrNoSolution) {
+		return Puzzle{X: x, Y: y, Z: z, Steps: depth(state)}, nil
+	}
+	if err != nil {
+		return Puzzle{}, fmt.Errorf("solving x=%d, y=%d, z=%d: %w", x, y, z, err)
+	}
+	return Puzzle{X: x, Y: y, Z: z, Solvable: true, Steps: len(solution.Steps)}, nil
+}
+
+// depth returns the distance to the farthest state reachable from the state,
+// visiting the states in order of distance as bfs does.
+func depth(state models.State) int {
+
+	distances := map[models.State]int{state: 0}
+	queue := []models.State{state}
+	farthest := 0
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, action := range models.Actions {
+			next := current.Apply(action)
+			if _, ok := distances[next]; ok {
+				continue
+			}
+			distances[next] = distances[current] + 1
+			farthest = distances[next]
+			queue = append(queue, next)
+		}
+	}
+	return farthest
+}
+
+func maxInt(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
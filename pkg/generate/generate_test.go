@@ -0,0 +1,88 @@
+package generate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/euclid"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/generate"
+)
+
+func TestGenerate(t *testing.T) {
+
+	for _, difficulty := range generate.Difficulties {
+		for _, unsolvable := range []bool{false, true} {
+			min, max, ok := difficulty.Steps()
+			require.True(t, ok)
+
+			set, err := generate.Generate(generate.Configuration{
+				Count:      10,
+				Difficulty: difficulty,
+				Unsolvable: unsolvable,
+				Seed:       1,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, difficulty, set.Difficulty)
+			assert.Equal(t, !unsolvable, set.Solvable)
+			require.Len(t, set.Puzzles, 10)
+
+			seen := map[generate.Puzzle]bool{}
+			for _, puzzle := range set.Puzzles {
+				assert.False(t, seen[puzzle], "puzzles are distinct")
+				seen[puzzle] = true
+
+				assert.Equal(t, !unsolvable, puzzle.Solvable)
+				assert.Equal(t, puzzle.Solvable, euclid.Solvable(puzzle.X, puzzle.Y, puzzle.Z))
+				assert.GreaterOrEqual(t, puzzle.Steps, min)
+				assert.LessOrEqual(t, puzzle.Steps, max)
+				assert.LessOrEqual(t, puzzle.X, generate.DefaultMaxCapacity)
+				assert.LessOrEqual(t, puzzle.Y, generate.DefaultMaxCapacity)
+			}
+		}
+	}
+}
+
+func TestSteps(t *testing.T) {
+
+	set, err := generate.Generate(generate.Configuration{Count: 50, Difficulty: generate.Easy, Seed: 3})
+	require.NoError(t, err)
+	for _, puzzle := range set.Puzzles {
+		a, err := euclid.Analyze(puzzle.X, puzzle.Y, puzzle.Z)
+		require.NoError(t, err)
+		require.True(t, a.Solvable)
+		assert.Equal(t, a.Counts.Min(), puzzle.Steps, "%+v", puzzle)
+	}
+}
+
+func TestSeed(t *testing.T) {
+
+	conf := generate.Configuration{Count: 5, Difficulty: generate.Medium, Seed: 42}
+	first, err := generate.Generate(conf)
+	require.NoError(t, err)
+	second, err := generate.Generate(conf)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	conf.Seed = 43
+	other, err := generate.Generate(conf)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Puzzles, other.Puzzles)
+}
+
+func TestInvalid(t *testing.T) {
+
+	tests := map[string]generate.Configuration{
+		"no count":           {Difficulty: generate.Easy},
+		"unknown difficulty": {Count: 1, Difficulty: "impossible"},
+		"tiny capacity":      {Count: 1, Difficulty: generate.Easy, MaxCapacity: 1},
+		"too hard":           {Count: 1, Difficulty: generate.Hard, MaxCapacity: 3},
+	}
+	for name, conf := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := generate.Generate(conf)
+			assert.Error(t, err)
+		})
+	}
+}
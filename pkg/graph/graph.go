@@ -0,0 +1,123 @@
+// Package graph builds the state graph of the water jug puzzle, the one the
+// bfs and dijkstra packages search, and writes it as Graphviz DOT.
+//
+// Every (w_x, w_y) tuple reachable from the starting amounts is a node, and
+// every models.Action taken on a node is an edge to the node it leads to.
+// Actions which leave the jugs as they were, such as emptying an empty jug,
+// are left out.
+//
+// Let's take x = 1, y = 1 as an example, starting from (0, 0).
+//
+//	(0, 0) -> Fill X -> (1, 0)
+//	(0, 0) -> Fill Y -> (0, 1)
+//	(1, 0) -> Fill Y -> (1, 1)
+//	(1, 0) -> Empty X -> (0, 0)
+//	(1, 0) -> Transfer to Y -> (0, 1)
+//	...
+//
+// The graph has at most (x+1)*(y+1) nodes and six times as many edges, so it
+// is only worth drawing for small capacities.
+package graph
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// Edge is an action taking the jugs from one state to another.
+type Edge struct {
+	From   models.State
+	To     models.State
+	Action models.Action
+}
+
+// Graph is every state reachable from Initial, in the order they are first
+// reached, along with the edges between them.
+type Graph struct {
+	Initial models.State
+	Nodes   []models.State
+	Edges   []Edge
+}
+
+// Build builds the graph of the states reachable from the baseState.
+//
+// A *models.AmountError is returned if the starting amounts are invalid.
+func Build(baseState models.State) (Graph, error) {
+
+	if baseState.Y.Capacity <= 0 || baseState.X.Capacity <= 0 {
+		return Graph{}, errors.New("both x and y must be positive")
+	}
+	if err := baseState.CheckAmounts(); err != nil {
+		return Graph{}, err
+	}
+
+	g := Graph{Initial: baseState, Nodes: []models.State{baseState}}
+	discovered := map[models.State]bool{baseState: true}
+	for i := 0; i < len(g.Nodes); i++ {
+		current := g.Nodes[i]
+		for _, action := range models.Actions {
+			next := current.Apply(action)
+			if next == current {
+				continue
+			}
+			g.Edges = append(g.Edges, Edge{From: current, To: next, Action: action})
+			if !discovered[next] {
+				discovered[next] = true
+				g.Nodes = append(g.Nodes, next)
+			}
+		}
+	}
+	return g, nil
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language. The initial state is
+// drawn bold, states reaching the goal are filled and the edges of the
+// solution, which must start from the initial state, are drawn red.
+//
+// The goal may be nil and the solution may be empty, nothing is highlighted
+// then.
+func (g Graph) WriteDOT(w io.Writer, goal models.Goal, solution models.Solution) error {
+
+	onPath := map[Edge]bool{}
+	from := g.Initial
+	for _, step := range solution.Steps {
+		onPath[Edge{From: from, To: step.State, Action: step.Action}] = true
+		from = step.State
+	}
+
+	output := bufio.NewWriter(w)
+	fmt.Fprintf(output, "digraph jugs {\n\tlabel=%q;\n\tnode [shape=circle];\n",
+		fmt.Sprintf("x = %d, y = %d", g.Initial.X.Capacity, g.Initial.Y.Capacity))
+	for _, node := range g.Nodes {
+		var attributes string
+		switch reached := goal != nil && goal.Reached(node.Jugs()); {
+		case node == g.Initial && reached:
+			attributes = `, shape=doublecircle, style="bold,filled", fillcolor=palegreen`
+		case node == g.Initial:
+			attributes = ", style=bold"
+		case reached:
+			attributes = ", shape=doublecircle, style=filled, fillcolor=palegreen"
+		}
+		fmt.Fprintf(output, "\t%s [label=\"(%d, %d)\"%s];\n",
+			id(node), node.X.Amount, node.Y.Amount, attributes)
+	}
+	for _, edge := range g.Edges {
+		var attributes string
+		if onPath[edge] {
+			attributes = ", color=red, fontcolor=red, penwidth=2"
+		}
+		fmt.Fprintf(output, "\t%s -> %s [label=%q%s];\n",
+			id(edge.From), id(edge.To), edge.Action, attributes)
+	}
+	fmt.Fprintln(output, "}")
+	return output.Flush()
+}
+
+// id is the DOT identifier of a node.
+func id(s models.State) string {
+	return fmt.Sprintf("\"%d_%d\"", s.X.Amount, s.Y.Amount)
+}
@@ -0,0 +1,106 @@
+package graph_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/graph"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvertest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+
+	t.Run("every reachable state is a node", func(t *testing.T) {
+		g, err := graph.Build(solvertest.NewState(3, 2))
+		require.NoError(t, err)
+
+		// Every reachable state has a full or an empty jug, (1, 1) is
+		// never reached.
+		assert.Len(t, g.Nodes, 10)
+		assert.NotContains(t, g.Nodes, models.State{
+			X: models.Jug{Capacity: 3, Amount: 1},
+			Y: models.Jug{Capacity: 2, Amount: 1},
+		})
+		assert.Equal(t, solvertest.NewState(3, 2), g.Nodes[0])
+	})
+
+	t.Run("edges apply their action", func(t *testing.T) {
+		g, err := graph.Build(solvertest.NewState(4, 3))
+		require.NoError(t, err)
+
+		for _, edge := range g.Edges {
+			assert.Equal(t, edge.To, edge.From.Apply(edge.Action))
+			assert.NotEqual(t, edge.From, edge.To, "actions doing nothing are left out")
+		}
+	})
+
+	t.Run("starting amounts are honored", func(t *testing.T) {
+		state := solvertest.NewState(4, 2)
+		state.X.Amount = 1
+
+		g, err := graph.Build(state)
+		require.NoError(t, err)
+		assert.Contains(t, g.Nodes, models.State{
+			X: models.Jug{Capacity: 4, Amount: 3},
+			Y: models.Jug{Capacity: 2, Amount: 0},
+		}, "odd amounts are reachable")
+	})
+
+	t.Run("invalid states are rejected", func(t *testing.T) {
+		_, err := graph.Build(solvertest.NewState(0, 2))
+		assert.Error(t, err)
+
+		state := solvertest.NewState(3, 2)
+		state.Y.Amount = 3
+		_, err = graph.Build(state)
+		var amountErr *models.AmountError
+		assert.ErrorAs(t, err, &amountErr)
+	})
+}
+
+func TestWriteDOT(t *testing.T) {
+
+	state := solvertest.NewState(1, 1)
+	g, err := graph.Build(state)
+	require.NoError(t, err)
+
+	t.Run("solution and goal are highlighted", func(t *testing.T) {
+		goal := models.InJug{Jug: models.JugY, Z: 1}
+		solution, err := bfs.SolveGoal(state, goal)
+		require.NoError(t, err)
+
+		output := &bytes.Buffer{}
+		require.NoError(t, g.WriteDOT(output, goal, solution))
+		assert.Equal(t, `digraph jugs {
+	label="x = 1, y = 1";
+	node [shape=circle];
+	"0_0" [label="(0, 0)", style=bold];
+	"1_0" [label="(1, 0)"];
+	"0_1" [label="(0, 1)", shape=doublecircle, style=filled, fillcolor=palegreen];
+	"1_1" [label="(1, 1)", shape=doublecircle, style=filled, fillcolor=palegreen];
+	"0_0" -> "1_0" [label="Fill X"];
+	"0_0" -> "0_1" [label="Fill Y", color=red, fontcolor=red, penwidth=2];
+	"1_0" -> "1_1" [label="Fill Y"];
+	"1_0" -> "0_0" [label="Empty X"];
+	"1_0" -> "0_1" [label="Transfer to Y"];
+	"0_1" -> "1_1" [label="Fill X"];
+	"0_1" -> "0_0" [label="Empty Y"];
+	"0_1" -> "1_0" [label="Transfer to X"];
+	"1_1" -> "0_1" [label="Empty X"];
+	"1_1" -> "1_0" [label="Empty Y"];
+}
+`, output.String())
+	})
+
+	t.Run("nothing is highlighted without a goal", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		require.NoError(t, g.WriteDOT(output, nil, models.Solution{}))
+		assert.NotContains(t, output.String(), "red")
+		assert.NotContains(t, output.String(), "filled")
+	})
+}
@@ -51,7 +51,10 @@
 package iterative
 
 import (
+	"context"
 	"errors"
+	"fmt"
+
 	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
 )
 
@@ -65,10 +68,19 @@ const (
 
 type step func(act action, from, to models.Jug)
 
-// Solve solves the water jugs riddle iteratively.
+type winning func(from, to models.Jug) bool
+
+// Solve solves the water jugs riddle iteratively, starting from the amounts in
+// the baseState.
 //
-// An error ErrNoSolution is returned if no solution exists.
+// An error ErrNoSolution is returned if no solution exists, a
+// *models.AmountError is returned if the starting amounts are invalid.
 func Solve(baseState models.State, z int) (models.Solution, error) {
+	return SolveContext(context.Background(), baseState, z)
+}
+
+// SolveContext is Solve, but it stops with ctx.Err() once ctx is done.
+func SolveContext(ctx context.Context, baseState models.State, z int) (models.Solution, error) {
 
 	x := baseState.X.Capacity
 	y := baseState.Y.Capacity
@@ -82,12 +94,107 @@ func Solve(baseState models.State, z int) (models.Solution, error) {
 		return models.Solution{}, errors.New("both x and z must be positive")
 	}
 
-	// We derive two solutions, first pouring from X to Y, secondly from Y to X
-	// we keep the minimum of both
+	return SolveGoalContext(ctx, baseState, models.AnyJug{Z: z})
+}
+
+// SolveGoal solves the water jugs riddle iteratively, starting from the
+// amounts in the baseState, until the goal is reached.
+//
+// Only the models.AnyJug and models.InJug goals are supported, as the
+// strategies are not guaranteed to reach any other goal, models.ErrUnsupported
+// is returned otherwise.
+//
+// An error ErrNoSolution is returned if no solution exists, a
+// *models.AmountError is returned if the starting amounts are invalid.
+func SolveGoal(baseState models.State, goal models.Goal) (models.Solution, error) {
+	return SolveGoalContext(context.Background(), baseState, goal)
+}
+
+// SolveGoalContext is SolveGoal, but it stops with ctx.Err() once ctx is done.
+func SolveGoalContext(ctx context.Context, baseState models.State, goal models.Goal) (models.Solution, error) {
+
+	switch goal.(type) {
+	case models.AnyJug, models.InJug:
+	default:
+		return models.Solution{}, fmt.Errorf("%w: goal %s", models.ErrUnsupported, goal)
+	}
+
+	if baseState.Y.Capacity <= 0 || baseState.X.Capacity <= 0 {
+		return models.Solution{}, errors.New("both x and y must be positive")
+	}
+	if err := baseState.CheckAmounts(); err != nil {
+		return models.Solution{}, err
+	}
+	if err := goal.Validate(baseState.Jugs()); err != nil {
+		return models.Solution{}, err
+	}
+
+	// Filling, transferring and emptying a full jug never change the total
+	// amount of water modulo gcd(x, y), so the water we start with may need
+	// to be thrown away first.
+	// For every way of emptying the jugs we derive two solutions, first
+	// pouring from X to Y, secondly from Y to X, we keep the minimum of all.
+	var (
+		best  models.Solution
+		found bool
+	)
+	for _, prefix := range emptyingPrefixes(baseState) {
+		state := baseState
+		if len(prefix) > 0 {
+			state = prefix[len(prefix)-1].State
+		}
+
+		for _, solve := range []func(context.Context, models.State, models.Goal) (models.Solution, error){solveXToY, solveYToX} {
+			s, err := solve(ctx, state, goal)
+			if errors.Is(err, models.ErrNoSolution) {
+				continue
+			}
+			if err != nil {
+				return models.Solution{}, err
+			}
+
+			s.Steps = append(prefix[:len(prefix):len(prefix)], s.Steps...)
+			if !found || len(s.Steps) <= len(best.Steps) {
+				best, found = s, true
+			}
+		}
+	}
+
+	if !found {
+		return models.Solution{}, models.ErrNoSolution
+	}
+	return best, nil
+}
+
+// emptyingPrefixes lists the ways of emptying the jugs holding water before
+// applying a strategy, starting with not emptying them at all.
+func emptyingPrefixes(state models.State) [][]models.Step {
+
+	prefixes := [][]models.Step{nil}
+	emptyX := models.Step{State: state.Apply(models.ActionEmptyX), Action: models.ActionEmptyX}
+	emptyY := models.Step{State: state.Apply(models.ActionEmptyY), Action: models.ActionEmptyY}
+	if state.X.Amount > 0 {
+		prefixes = append(prefixes, []models.Step{emptyX})
+	}
+	if state.Y.Amount > 0 {
+		prefixes = append(prefixes, []models.Step{emptyY})
+	}
+	if state.X.Amount > 0 && state.Y.Amount > 0 {
+		prefixes = append(prefixes, []models.Step{emptyX, {
+			State:  emptyX.State.Apply(models.ActionEmptyY),
+			Action: models.ActionEmptyY,
+		}})
+	}
+	return prefixes
+}
+
+// solveXToY solves the riddle filling X and transferring to Y.
+func solveXToY(ctx context.Context, state models.State, goal models.Goal) (models.Solution, error) {
 	s1 := models.Solution{}
 	err := solveFromTo(
-		baseState.X,
-		baseState.Y,
+		ctx,
+		state.X,
+		state.Y,
 		// The callback adds a solution step, knowing that the From Jug is X
 		// and the To Jug is Y.
 		func(act action, from, to models.Jug) {
@@ -107,16 +214,19 @@ func Solve(baseState models.State, z int) (models.Solution, error) {
 			}
 			s1.Steps = append(s1.Steps, s)
 		},
-		z)
-
-	if err != nil {
-		return models.Solution{}, err
-	}
+		func(from, to models.Jug) bool {
+			return goal.Reached([]models.Jug{from, to})
+		})
+	return s1, err
+}
 
+// solveYToX solves the riddle filling Y and transferring to X.
+func solveYToX(ctx context.Context, state models.State, goal models.Goal) (models.Solution, error) {
 	s2 := models.Solution{}
-	err = solveFromTo(
-		baseState.Y,
-		baseState.X,
+	err := solveFromTo(
+		ctx,
+		state.Y,
+		state.X,
 		func(act action, from, to models.Jug) {
 			s := models.Step{
 				State: models.State{
@@ -134,15 +244,10 @@ func Solve(baseState models.State, z int) (models.Solution, error) {
 			}
 			s2.Steps = append(s2.Steps, s)
 		},
-		z)
-	if err != nil {
-		return models.Solution{}, err
-	}
-
-	if len(s1.Steps) < len(s2.Steps) {
-		return s1, nil
-	}
-	return s2, nil
+		func(from, to models.Jug) bool {
+			return goal.Reached([]models.Jug{to, from})
+		})
+	return s2, err
 }
 
 // solveFromTo helps abstract the algorithm from the expected Solution format.
@@ -155,13 +260,20 @@ func Solve(baseState models.State, z int) (models.Solution, error) {
 // is generated.
 // This callback allows and helps formatting the Solution correctly avoiding too
 // much code repetition.
+// The "winning" method works the same way, checking the goal knowing which jug
+// is which.
+//
+// Large capacities may take long to cycle, so ctx is checked on every
+// iteration, ctx.Err() is returned once it is done.
 func solveFromTo(
+	ctx context.Context,
 	from models.Jug, to models.Jug,
 	newStep step,
-	z int) error {
+	won winning) error {
 
-	// If z is 0 we already have a solution, and that is doing nothing
-	if z == 0 {
+	// If we already won we already have a solution, and that is doing
+	// nothing, as when z is 0 and the jugs start empty.
+	if won(from, to) {
 		return nil
 	}
 
@@ -171,15 +283,20 @@ func solveFromTo(
 		from, to models.Jug
 	}
 
-	// We start by filling the from.
+	// We start by filling the from, unless it already has some water.
 	// This allows checking for the winning condition, the only time this action
 	// "wins" is the first time we fill the from jug.
-	from.Amount = from.Capacity
-	newStep(fillFrom, from, to)
+	if from.Amount == 0 {
+		from.Amount = from.Capacity
+		newStep(fillFrom, from, to)
+	}
 
 	visitedTuples := map[tuple]bool{}
-	for from.Amount != z && to.Amount != z && !visitedTuples[tuple{from: from, to: to}] {
+	for !won(from, to) && !visitedTuples[tuple{from: from, to: to}] {
 
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		visitedTuples[tuple{from: from, to: to}] = true
 
 		if to.Amount == to.Capacity {
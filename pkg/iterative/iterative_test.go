@@ -1,8 +1,13 @@
 package iterative_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
 	"github.com/nacho692/live-free-or-die-jugging/pkg/iterative"
 	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvertest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -37,6 +42,74 @@ func TestInvalid(t *testing.T) {
 		_, err := iterative.Solve(newBaseState(5, 3), 10)
 		assert.Error(t, err)
 	})
+
+	t.Run("amounts should not exceed the capacity", func(t *testing.T) {
+		state := solvertest.NewState(5, 3)
+		state.Y.Amount = 4
+		_, err := iterative.Solve(state, 1)
+
+		var amountErr *models.AmountError
+		require.ErrorAs(t, err, &amountErr)
+		assert.Equal(t, "Y", amountErr.Jug)
+	})
+
+	t.Run("amounts should not be negative", func(t *testing.T) {
+		state := solvertest.NewState(5, 3)
+		state.X.Amount = -1
+		_, err := iterative.Solve(state, 1)
+
+		var amountErr *models.AmountError
+		require.ErrorAs(t, err, &amountErr)
+		assert.Equal(t, "X", amountErr.Jug)
+	})
+}
+
+func TestGoals(t *testing.T) {
+
+	t.Run("z in a specific jug", func(t *testing.T) {
+		solution, err := iterative.SolveGoal(solvertest.NewState(5, 3), models.InJug{Jug: models.JugY, Z: 2})
+		require.NoError(t, err)
+
+		expectedSolution := models.Solution{
+			Steps: []models.Step{
+				{
+					State: models.State{
+						X: models.Jug{Capacity: 5, Amount: 5},
+						Y: models.Jug{Capacity: 3, Amount: 0},
+					},
+					Action: models.ActionFillX,
+				},
+				{
+					State: models.State{
+						X: models.Jug{Capacity: 5, Amount: 2},
+						Y: models.Jug{Capacity: 3, Amount: 3},
+					},
+					Action: models.ActionTransferY,
+				},
+				{
+					State: models.State{
+						X: models.Jug{Capacity: 5, Amount: 2},
+						Y: models.Jug{Capacity: 3, Amount: 0},
+					},
+					Action: models.ActionEmptyY,
+				},
+				{
+					State: models.State{
+						X: models.Jug{Capacity: 5, Amount: 0},
+						Y: models.Jug{Capacity: 3, Amount: 2},
+					},
+					Action: models.ActionTransferY,
+				},
+			},
+		}
+
+		assert.Equal(t, expectedSolution, solution)
+	})
+
+	t.Run("sum is unsupported", func(t *testing.T) {
+		_, err := iterative.SolveGoal(solvertest.NewState(5, 3), models.Sum{Z: 8})
+		assert.ErrorIs(t, err, models.ErrUnsupported)
+	})
 }
 
 func TestSolutions(t *testing.T) {
@@ -191,6 +264,121 @@ func TestSolutions(t *testing.T) {
 		require.NoError(t, err)
 		assert.Len(t, s.Steps, 0)
 	})
+
+	t.Run("starting amounts should be honored", func(t *testing.T) {
+		state := solvertest.NewState(5, 4)
+		state.X.Amount = 2
+		state.Y.Amount = 3
+
+		solution, err := iterative.Solve(state, 1)
+		require.NoError(t, err)
+
+		expectedSolution := models.Solution{
+			Steps: []models.Step{
+				{
+					State: models.State{
+						X: models.Jug{Capacity: 5, Amount: 1},
+						Y: models.Jug{Capacity: 4, Amount: 4},
+					},
+					Action: models.ActionTransferY,
+				},
+			},
+		}
+
+		assert.Equal(t, expectedSolution, solution)
+	})
+
+	t.Run("starting water might need to be emptied", func(t *testing.T) {
+		state := solvertest.NewState(6, 9)
+		state.X.Amount = 1
+
+		solution, err := iterative.Solve(state, 3)
+		require.NoError(t, err)
+
+		expectedSolution := models.Solution{
+			Steps: []models.Step{
+				{
+					State: models.State{
+						X: models.Jug{Capacity: 6, Amount: 0},
+						Y: models.Jug{Capacity: 9, Amount: 0},
+					},
+					Action: models.ActionEmptyX,
+				},
+				{
+					State: models.State{
+						X: models.Jug{Capacity: 6, Amount: 0},
+						Y: models.Jug{Capacity: 9, Amount: 9},
+					},
+					Action: models.ActionFillY,
+				},
+				{
+					State: models.State{
+						X: models.Jug{Capacity: 6, Amount: 6},
+						Y: models.Jug{Capacity: 9, Amount: 3},
+					},
+					Action: models.ActionTransferX,
+				},
+			},
+		}
+
+		assert.Equal(t, expectedSolution, solution)
+	})
+
+	t.Run("z already measured should take 0 steps", func(t *testing.T) {
+		state := solvertest.NewState(5, 3)
+		state.Y.Amount = 2
+
+		s, err := iterative.Solve(state, 2)
+		require.NoError(t, err)
+		assert.Len(t, s.Steps, 0)
+	})
+}
+
+// TestValid replays every solution for small puzzles, from any starting
+// amounts.
+func TestValid(t *testing.T) {
+
+	for x := 1; x <= 8; x++ {
+		for y := 1; y <= 8; y++ {
+			t.Run(fmt.Sprintf("x=%d, y=%d", x, y), func(t *testing.T) {
+				for wx := 0; wx <= x; wx++ {
+					for wy := 0; wy <= y; wy++ {
+						state := solvertest.NewState(x, y)
+						state.X.Amount, state.Y.Amount = wx, wy
+						for z := 0; z <= x || z <= y; z++ {
+							solution, err := iterative.Solve(state, z)
+							if errors.Is(err, models.ErrNoSolution) {
+								continue
+							}
+							require.NoError(t, err)
+							require.NoError(t, models.Validate(state, models.AnyJug{Z: z}, solution))
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := iterative.SolveContext(ctx, solvertest.NewState(1_000_003, 1_000_033), 2)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	s, err := iterative.SolveContext(ctx, solvertest.NewState(5, 3), 0)
+	require.NoError(t, err, "nothing to search for")
+	assert.Len(t, s.Steps, 0)
+}
+
+func TestConformance(t *testing.T) {
+	solvertest.TestSolver(t, app.SolverFun(iterative.Solve))
+}
+
+func FuzzSolve(f *testing.F) {
+	solvertest.FuzzSolver(f, app.SolverFun(iterative.Solve))
 }
 
 func newBaseState(x, y int) models.State {
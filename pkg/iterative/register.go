@@ -0,0 +1,12 @@
+package iterative
+
+import "github.com/nacho692/live-free-or-die-jugging/pkg/solvers"
+
+func init() {
+	solvers.Register(solvers.Registration{
+		Name:        "iterative",
+		Description: "pours one jug into the other both ways, fast but only for goals on a single jug",
+		Solve:       SolveContext,
+		SolveGoal:   SolveGoalContext,
+	})
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Costs is the cost of taking each Action, as in the time it takes or the
+// water it wastes. Actions missing from it cost nothing.
+type Costs map[Action]int
+
+// UnitCosts makes every action cost 1, so the cost of a solution is its
+// amount of steps.
+var UnitCosts = Costs{
+	ActionFillX:     1,
+	ActionFillY:     1,
+	ActionEmptyX:    1,
+	ActionEmptyY:    1,
+	ActionTransferX: 1,
+	ActionTransferY: 1,
+}
+
+// costKinds are the shorthands for setting the cost of both actions of a kind.
+var costKinds = map[string][]Action{
+	"fill":     {ActionFillX, ActionFillY},
+	"empty":    {ActionEmptyX, ActionEmptyY},
+	"transfer": {ActionTransferX, ActionTransferY},
+}
+
+// ParseCosts parses comma separated costs, as in "fill=3,transfer=0". Every
+// action costs 1 unless it is set, either by its identifier, see Action.ID, or
+// by its kind: fill, empty or transfer. Identifiers take precedence.
+func ParseCosts(s string) (Costs, error) {
+	costs := Costs{}
+	for action, cost := range UnitCosts {
+		costs[action] = cost
+	}
+
+	byID := Costs{}
+	for _, field := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid cost %q, name=cost was expected", field)
+		}
+		cost, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cost %q, a number was expected", field)
+		}
+		if actions, ok := costKinds[name]; ok {
+			for _, action := range actions {
+				costs[action] = cost
+			}
+			continue
+		}
+		action, err := ParseAction(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cost %q, unknown action", field)
+		}
+		byID[action] = cost
+	}
+	for action, cost := range byID {
+		costs[action] = cost
+	}
+	return costs, costs.Validate()
+}
+
+// Validate returns an error if any cost is negative, as the cheapest solution
+// would not be well defined.
+func (c Costs) Validate() error {
+	for _, action := range Actions {
+		if c[action] < 0 {
+			return fmt.Errorf("the cost of %s must be zero or greater", action.ID())
+		}
+	}
+	return nil
+}
+
+// Total returns the cost of taking every step.
+func (c Costs) Total(steps []Step) int {
+	total := 0
+	for _, step := range steps {
+		total += c[step.Action]
+	}
+	return total
+}
+
+// WaterUsed returns the amount of water taken from the lake by filling jugs,
+// starting from the initial state.
+func WaterUsed(initial State, steps []Step) int {
+	used := 0
+	previous := initial
+	for _, step := range steps {
+		switch step.Action {
+		case ActionFillX:
+			used += step.State.X.Amount - previous.X.Amount
+		case ActionFillY:
+			used += step.State.Y.Amount - previous.Y.Amount
+		}
+		previous = step.State
+	}
+	return used
+}
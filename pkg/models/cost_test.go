@@ -0,0 +1,53 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCosts(t *testing.T) {
+
+	costs, err := models.ParseCosts("fill_x=5, fill=3,transfer=0")
+	require.NoError(t, err)
+	assert.Equal(t, models.Costs{
+		models.ActionFillX:     5,
+		models.ActionFillY:     3,
+		models.ActionEmptyX:    1,
+		models.ActionEmptyY:    1,
+		models.ActionTransferX: 0,
+		models.ActionTransferY: 0,
+	}, costs, "identifiers take precedence over kinds")
+
+	invalid := []string{"", "fill", "fill=cheap", "drink=1", "empty=-1"}
+	for _, s := range invalid {
+		_, err = models.ParseCosts(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestWaterUsed(t *testing.T) {
+
+	state := models.State{
+		X: models.Jug{Capacity: 5, Amount: 2},
+		Y: models.Jug{Capacity: 3},
+	}
+	var steps []models.Step
+	for _, action := range []models.Action{
+		models.ActionFillX, models.ActionTransferY, models.ActionEmptyY, models.ActionFillY,
+	} {
+		state = state.Apply(action)
+		steps = append(steps, models.Step{State: state, Action: action})
+	}
+
+	initial := models.State{
+		X: models.Jug{Capacity: 5, Amount: 2},
+		Y: models.Jug{Capacity: 3},
+	}
+	assert.Equal(t, 6, models.WaterUsed(initial, steps), "3 to fill X and 3 to fill Y")
+	assert.Equal(t, 4, models.UnitCosts.Total(steps))
+	assert.Equal(t, 0, models.Costs{}.Total(steps))
+}
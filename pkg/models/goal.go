@@ -0,0 +1,211 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Indexes of the two jugs State, when seen as a MultiState.
+const (
+	JugX = 0
+	JugY = 1
+)
+
+// Goal decides whether the jugs measure what the puzzle asks for.
+//
+// Goals work on the jugs slice so they apply to both State and MultiState.
+type Goal interface {
+	// Reached reports whether the jugs are a solution to the puzzle.
+	Reached(jugs []Jug) bool
+	// Validate returns an error if the goal cannot be measured with the jugs
+	// capacities no matter what, as in being out of bounds.
+	Validate(jugs []Jug) error
+	fmt.Stringer
+}
+
+// Jugs returns the X and Y jugs, in that order.
+func (s State) Jugs() []Jug {
+	return []Jug{s.X, s.Y}
+}
+
+// AnyJug is reached when any of the jugs has Z amount of water in it, this is
+// the goal of the original riddle.
+type AnyJug struct {
+	Z int
+}
+
+func (g AnyJug) Reached(jugs []Jug) bool {
+	for _, jug := range jugs {
+		if jug.Amount == g.Z {
+			return true
+		}
+	}
+	return false
+}
+
+func (g AnyJug) Validate(jugs []Jug) error {
+	if g.Z < 0 {
+		return errors.New("z must be zero or greater")
+	}
+	for _, jug := range jugs {
+		if g.Z <= jug.Capacity {
+			return nil
+		}
+	}
+	return errors.New("z must be smaller than at least one of the jugs")
+}
+
+func (g AnyJug) String() string {
+	return fmt.Sprintf("%d in any jug", g.Z)
+}
+
+// InJug is reached when a specific jug, referenced by its index, has Z amount
+// of water in it.
+type InJug struct {
+	Jug int
+	Z   int
+}
+
+func (g InJug) Reached(jugs []Jug) bool {
+	return g.Jug >= 0 && g.Jug < len(jugs) && jugs[g.Jug].Amount == g.Z
+}
+
+func (g InJug) Validate(jugs []Jug) error {
+	if g.Jug < 0 || g.Jug >= len(jugs) {
+		return fmt.Errorf("there is no jug %s", jugName(g.Jug, len(jugs)))
+	}
+	if g.Z < 0 {
+		return errors.New("z must be zero or greater")
+	}
+	if g.Z > jugs[g.Jug].Capacity {
+		return fmt.Errorf("z must not exceed jug %s", jugName(g.Jug, len(jugs)))
+	}
+	return nil
+}
+
+func (g InJug) String() string {
+	return fmt.Sprintf("%d in jug %s", g.Z, jugName(g.Jug, 2))
+}
+
+// Sum is reached when all the jugs together have Z amount of water in them.
+type Sum struct {
+	Z int
+}
+
+func (g Sum) Reached(jugs []Jug) bool {
+	total := 0
+	for _, jug := range jugs {
+		total += jug.Amount
+	}
+	return total == g.Z
+}
+
+func (g Sum) Validate(jugs []Jug) error {
+	if g.Z < 0 {
+		return errors.New("z must be zero or greater")
+	}
+	total := 0
+	for _, jug := range jugs {
+		total += jug.Capacity
+	}
+	if g.Z > total {
+		return errors.New("z must not exceed the sum of the jugs")
+	}
+	return nil
+}
+
+func (g Sum) String() string {
+	return fmt.Sprintf("%d in total", g.Z)
+}
+
+// Exact is reached when every jug has exactly the amount of water in the same
+// position of Amounts.
+type Exact struct {
+	Amounts []int
+}
+
+func (g Exact) Reached(jugs []Jug) bool {
+	if len(jugs) != len(g.Amounts) {
+		return false
+	}
+	for i, jug := range jugs {
+		if jug.Amount != g.Amounts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (g Exact) Validate(jugs []Jug) error {
+	if len(jugs) != len(g.Amounts) {
+		return fmt.Errorf("there must be an amount for each of the %d jugs", len(jugs))
+	}
+	for i, jug := range jugs {
+		if g.Amounts[i] < 0 || g.Amounts[i] > jug.Capacity {
+			return fmt.Errorf("the amount for jug %s must be between 0 and its capacity",
+				jugName(i, len(jugs)))
+		}
+	}
+	return nil
+}
+
+func (g Exact) String() string {
+	amounts := make([]string, len(g.Amounts))
+	for i, amount := range g.Amounts {
+		amounts[i] = strconv.Itoa(amount)
+	}
+	return "exactly (" + strings.Join(amounts, ", ") + ")"
+}
+
+// GoalKind names the built-in goals, it allows choosing one from a text input.
+type GoalKind string
+
+const (
+	GoalAny   GoalKind = "either"
+	GoalX     GoalKind = "x"
+	GoalY     GoalKind = "y"
+	GoalSum   GoalKind = "sum"
+	GoalExact GoalKind = "exact"
+)
+
+// GoalKinds lists every built-in goal kind.
+var GoalKinds = []GoalKind{GoalAny, GoalX, GoalY, GoalSum, GoalExact}
+
+// NewGoal builds the built-in Goal of the given kind.
+//
+// GoalExact requires a target amount for each jug, every other kind requires
+// a single z target.
+func NewGoal(kind GoalKind, targets ...int) (Goal, error) {
+	if kind == GoalExact {
+		return Exact{Amounts: targets}, nil
+	}
+	if len(targets) != 1 {
+		return nil, fmt.Errorf("goal %s requires a single target", kind)
+	}
+
+	z := targets[0]
+	switch kind {
+	case GoalAny:
+		return AnyJug{Z: z}, nil
+	case GoalX:
+		return InJug{Jug: JugX, Z: z}, nil
+	case GoalY:
+		return InJug{Jug: JugY, Z: z}, nil
+	case GoalSum:
+		return Sum{Z: z}, nil
+	}
+	return nil, fmt.Errorf("unknown goal %q", kind)
+}
+
+// jugName names the i-th jug, X and Y for two jugs, its number otherwise.
+func jugName(i, n int) string {
+	if n == 2 && i == JugX {
+		return "X"
+	}
+	if n == 2 && i == JugY {
+		return "Y"
+	}
+	return strconv.Itoa(i + 1)
+}
@@ -0,0 +1,74 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGoal(t *testing.T) {
+
+	t.Run("every kind with a single target", func(t *testing.T) {
+		expected := map[models.GoalKind]models.Goal{
+			models.GoalAny: models.AnyJug{Z: 3},
+			models.GoalX:   models.InJug{Jug: models.JugX, Z: 3},
+			models.GoalY:   models.InJug{Jug: models.JugY, Z: 3},
+			models.GoalSum: models.Sum{Z: 3},
+		}
+		for kind, goal := range expected {
+			actual, err := models.NewGoal(kind, 3)
+			require.NoError(t, err)
+			assert.Equal(t, goal, actual)
+		}
+	})
+
+	t.Run("exact requires every amount", func(t *testing.T) {
+		goal, err := models.NewGoal(models.GoalExact, 2, 3)
+		require.NoError(t, err)
+		assert.Equal(t, models.Exact{Amounts: []int{2, 3}}, goal)
+	})
+
+	t.Run("single target kinds reject several targets", func(t *testing.T) {
+		_, err := models.NewGoal(models.GoalSum, 2, 3)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		_, err := models.NewGoal("half", 3)
+		assert.Error(t, err)
+	})
+}
+
+func TestGoalValidate(t *testing.T) {
+
+	jugs := models.State{
+		X: models.Jug{Capacity: 5},
+		Y: models.Jug{Capacity: 3},
+	}.Jugs()
+
+	valid := []models.Goal{
+		models.AnyJug{Z: 5},
+		models.InJug{Jug: models.JugY, Z: 3},
+		models.Sum{Z: 8},
+		models.Exact{Amounts: []int{5, 0}},
+	}
+	for _, goal := range valid {
+		assert.NoError(t, goal.Validate(jugs), goal.String())
+	}
+
+	invalid := []models.Goal{
+		models.AnyJug{Z: 6},
+		models.AnyJug{Z: -1},
+		models.InJug{Jug: models.JugY, Z: 4},
+		models.InJug{Jug: 2, Z: 1},
+		models.Sum{Z: 9},
+		models.Exact{Amounts: []int{5}},
+		models.Exact{Amounts: []int{5, 4}},
+	}
+	for _, goal := range invalid {
+		assert.Error(t, goal.Validate(jugs), goal.String())
+	}
+}
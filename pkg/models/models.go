@@ -5,39 +5,111 @@
 // generate a valid model to output.
 //
 // It is on the input and solver side to actually construct a valid model, as
-// it is not easy to disallow invalid states.
+// it is not easy to disallow invalid states. Validate checks a solver did.
 package models
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // ErrNoSolution indicates that there is no solution for the puzzle
 var ErrNoSolution = errors.New("no solution")
 
+// ErrUnsupported indicates that the solver cannot handle the puzzle, even
+// though it may have a solution.
+var ErrUnsupported = errors.New("unsupported by the solver")
+
+// AmountError indicates that a jug holds a negative amount of water or more
+// than its capacity.
+type AmountError struct {
+	// Jug names the invalid jug.
+	Jug      string
+	Amount   int
+	Capacity int
+}
+
+func (e *AmountError) Error() string {
+	return fmt.Sprintf("jug %s amount %d must be between 0 and its capacity %d",
+		e.Jug, e.Amount, e.Capacity)
+}
+
 // Action is a user-friendly text indicating the action taken
 type Action string
 
 const (
-	ActionFillX     = "Fill X"
-	ActionFillY     = "Fill Y"
-	ActionTransferX = "Transfer to X"
-	ActionTransferY = "Transfer to Y"
-	ActionEmptyX    = "Empty X"
-	ActionEmptyY    = "Empty Y"
+	ActionFillX     Action = "Fill X"
+	ActionFillY     Action = "Fill Y"
+	ActionTransferX Action = "Transfer to X"
+	ActionTransferY Action = "Transfer to Y"
+	ActionEmptyX    Action = "Empty X"
+	ActionEmptyY    Action = "Empty Y"
 )
 
+// Actions lists every action that can be taken on a State.
+var Actions = []Action{
+	ActionFillX,
+	ActionFillY,
+	ActionEmptyX,
+	ActionEmptyY,
+	ActionTransferX,
+	ActionTransferY,
+}
+
 // State a State indicates the current state of the X and Y Jugs
 type State struct {
-	X Jug
-	Y Jug
+	X Jug `json:"x"`
+	Y Jug `json:"y"`
+}
+
+// CheckAmounts returns an *AmountError if any jug holds an invalid amount of
+// water.
+func (s State) CheckAmounts() error {
+	if err := s.X.checkAmount("X"); err != nil {
+		return err
+	}
+	return s.Y.checkAmount("Y")
+}
+
+// Apply returns the State reached after taking the action a.
+//
+// Capacities are never modified, unknown actions leave the State untouched.
+func (s State) Apply(a Action) State {
+	switch a {
+	case ActionFillX:
+		s.X.Amount = s.X.Capacity
+	case ActionFillY:
+		s.Y.Amount = s.Y.Capacity
+	case ActionEmptyX:
+		s.X.Amount = 0
+	case ActionEmptyY:
+		s.Y.Amount = 0
+	case ActionTransferX:
+		s.Y, s.X = pour(s.Y, s.X)
+	case ActionTransferY:
+		s.X, s.Y = pour(s.X, s.Y)
+	}
+	return s
+}
+
+// pour transfers as much water as possible from the "from" Jug to the "to" Jug.
+func pour(from, to Jug) (Jug, Jug) {
+	transfer := to.Capacity - to.Amount
+	if from.Amount < transfer {
+		transfer = from.Amount
+	}
+	from.Amount -= transfer
+	to.Amount += transfer
+	return from, to
 }
 
 // Step simply joins a state and the action that got there.
 type Step struct {
 	// State indicates the step after the action is taken
-	State State
+	State State `json:"state"`
 	// Action indicates the action that arrived at this state.
 	// It can be deduced by checking the previous step.
-	Action Action
+	Action Action `json:"action"`
 }
 
 type Solution struct {
@@ -47,11 +119,18 @@ type Solution struct {
 	// The last step must be a solution to the problem.
 	// Meaning that either the X Jug or the Y Jug have z amount of water in
 	// them.
-	Steps []Step
+	Steps []Step `json:"steps"`
 }
 
 // Jug a Jug carries a certain amount of water.
 type Jug struct {
-	Capacity int
-	Amount   int
+	Capacity int `json:"capacity"`
+	Amount   int `json:"amount"`
+}
+
+func (j Jug) checkAmount(name string) error {
+	if j.Amount < 0 || j.Amount > j.Capacity {
+		return &AmountError{Jug: name, Amount: j.Amount, Capacity: j.Capacity}
+	}
+	return nil
 }
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MoveKind indicates what a Move does with the indexed jugs.
+type MoveKind string
+
+const (
+	MoveFill  MoveKind = "fill"
+	MoveEmpty MoveKind = "empty"
+	MovePour  MoveKind = "pour"
+)
+
+// Move is the generalisation of Action for any number of jugs, jugs are
+// referenced by their index in MultiState.Jugs.
+type Move struct {
+	Kind MoveKind `json:"kind"`
+	// From is the filled or emptied jug, or the one poured from.
+	From int `json:"from"`
+	// To is the jug poured into, only meaningful for MovePour.
+	To int `json:"to"`
+}
+
+// Fill returns the Move filling the i-th jug.
+func Fill(i int) Move {
+	return Move{Kind: MoveFill, From: i}
+}
+
+// Empty returns the Move emptying the i-th jug.
+func Empty(i int) Move {
+	return Move{Kind: MoveEmpty, From: i}
+}
+
+// Pour returns the Move transferring as much water as possible from the i-th
+// jug to the j-th jug.
+func Pour(i, j int) Move {
+	return Move{Kind: MovePour, From: i, To: j}
+}
+
+// String returns a user-friendly text for the Move, jugs are numbered from 1.
+func (m Move) String() string {
+	switch m.Kind {
+	case MoveFill:
+		return fmt.Sprintf("Fill jug %d", m.From+1)
+	case MoveEmpty:
+		return fmt.Sprintf("Empty jug %d", m.From+1)
+	case MovePour:
+		return fmt.Sprintf("Transfer jug %d to jug %d", m.From+1, m.To+1)
+	}
+	return string(m.Kind)
+}
+
+// MultiState indicates the current state of any number of jugs.
+//
+// State is the special case of two jugs, where X is the first one and Y the
+// second one.
+type MultiState struct {
+	Jugs []Jug `json:"jugs"`
+}
+
+// Multi returns the State as a MultiState with two jugs.
+func (s State) Multi() MultiState {
+	return MultiState{Jugs: []Jug{s.X, s.Y}}
+}
+
+// State returns the MultiState as a two jugs State, ok is false if there are
+// not exactly two jugs.
+func (s MultiState) State() (state State, ok bool) {
+	if len(s.Jugs) != 2 {
+		return State{}, false
+	}
+	return State{X: s.Jugs[0], Y: s.Jugs[1]}, true
+}
+
+// CheckAmounts returns an *AmountError if any jug holds an invalid amount of
+// water, jugs are numbered from 1.
+func (s MultiState) CheckAmounts() error {
+	for i, jug := range s.Jugs {
+		if err := jug.checkAmount(strconv.Itoa(i + 1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Moves lists every move that can be taken on the MultiState.
+func (s MultiState) Moves() []Move {
+	moves := make([]Move, 0, len(s.Jugs)*(len(s.Jugs)+1))
+	for i := range s.Jugs {
+		moves = append(moves, Fill(i))
+	}
+	for i := range s.Jugs {
+		moves = append(moves, Empty(i))
+	}
+	for i := range s.Jugs {
+		for j := range s.Jugs {
+			if i != j {
+				moves = append(moves, Pour(i, j))
+			}
+		}
+	}
+	return moves
+}
+
+// Apply returns the MultiState reached after taking the move m, the receiver
+// is never modified.
+//
+// Capacities are never modified, moves referencing unknown jugs leave the
+// MultiState untouched.
+func (s MultiState) Apply(m Move) MultiState {
+	jugs := make([]Jug, len(s.Jugs))
+	copy(jugs, s.Jugs)
+	next := MultiState{Jugs: jugs}
+
+	valid := func(i int) bool {
+		return i >= 0 && i < len(jugs)
+	}
+	switch {
+	case m.Kind == MoveFill && valid(m.From):
+		jugs[m.From].Amount = jugs[m.From].Capacity
+	case m.Kind == MoveEmpty && valid(m.From):
+		jugs[m.From].Amount = 0
+	case m.Kind == MovePour && valid(m.From) && valid(m.To) && m.From != m.To:
+		jugs[m.From], jugs[m.To] = pour(jugs[m.From], jugs[m.To])
+	}
+	return next
+}
+
+// String formats the MultiState as (w_1/c_1, w_2/c_2, ...), the current amount
+// of water over max capacity for each jug.
+func (s MultiState) String() string {
+	jugs := make([]string, len(s.Jugs))
+	for i, jug := range s.Jugs {
+		jugs[i] = fmt.Sprintf("%d/%d", jug.Amount, jug.Capacity)
+	}
+	return "(" + strings.Join(jugs, ", ") + ")"
+}
+
+// MultiStep is the generalisation of Step for any number of jugs.
+type MultiStep struct {
+	// State indicates the step after the move is taken
+	State MultiState `json:"state"`
+	// Move indicates the move that arrived at this state.
+	Move Move `json:"move"`
+}
+
+// MultiSolution is the generalisation of Solution for any number of jugs.
+type MultiSolution struct {
+	// Steps follow the same rules as Solution.Steps, the last step must have
+	// z amount of water in any of the jugs.
+	Steps []MultiStep `json:"steps"`
+}
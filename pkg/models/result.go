@@ -0,0 +1,147 @@
+package models
+
+import "fmt"
+
+// actionIDs are the stable identifiers of every Action, the user-friendly
+// text may change but these must not.
+var actionIDs = map[Action]string{
+	ActionFillX:     "fill_x",
+	ActionFillY:     "fill_y",
+	ActionTransferX: "transfer_x",
+	ActionTransferY: "transfer_y",
+	ActionEmptyX:    "empty_x",
+	ActionEmptyY:    "empty_y",
+}
+
+// ID returns the stable identifier of the Action, as in "fill_x".
+func (a Action) ID() string {
+	return actionIDs[a]
+}
+
+// ParseAction returns the Action identified by id, see Action.ID.
+func ParseAction(id string) (Action, error) {
+	for action, actionID := range actionIDs {
+		if actionID == id {
+			return action, nil
+		}
+	}
+	return "", fmt.Errorf("unknown action %q", id)
+}
+
+// MarshalText encodes the Action as its stable identifier.
+func (a Action) MarshalText() ([]byte, error) {
+	id := a.ID()
+	if id == "" {
+		return nil, fmt.Errorf("unknown action %q", string(a))
+	}
+	return []byte(id), nil
+}
+
+// UnmarshalText decodes the Action from its stable identifier.
+func (a *Action) UnmarshalText(text []byte) error {
+	action, err := ParseAction(string(text))
+	if err != nil {
+		return err
+	}
+	*a = action
+	return nil
+}
+
+// GoalSpec describes a built-in Goal, it allows serialising goals.
+type GoalSpec struct {
+	Kind GoalKind `json:"kind"`
+	// Targets are the arguments for NewGoal.
+	Targets []int `json:"targets"`
+}
+
+// SpecOf describes the goal, ok is false if it is not a built-in goal.
+func SpecOf(goal Goal) (spec GoalSpec, ok bool) {
+	switch g := goal.(type) {
+	case AnyJug:
+		return GoalSpec{Kind: GoalAny, Targets: []int{g.Z}}, true
+	case InJug:
+		if g.Jug == JugX {
+			return GoalSpec{Kind: GoalX, Targets: []int{g.Z}}, true
+		}
+		if g.Jug == JugY {
+			return GoalSpec{Kind: GoalY, Targets: []int{g.Z}}, true
+		}
+	case Sum:
+		return GoalSpec{Kind: GoalSum, Targets: []int{g.Z}}, true
+	case Exact:
+		return GoalSpec{Kind: GoalExact, Targets: g.Amounts}, true
+	}
+	return GoalSpec{}, false
+}
+
+// Goal builds the described Goal.
+func (s GoalSpec) Goal() (Goal, error) {
+	return NewGoal(s.Kind, s.Targets...)
+}
+
+// Result joins a puzzle and its Solution, it is meant to be serialised.
+type Result struct {
+	// Initial is the state the puzzle starts from.
+	Initial State `json:"initial"`
+	// Goal is nil for goals which are not built-in.
+	Goal      *GoalSpec `json:"goal"`
+	Solvable  bool      `json:"solvable"`
+	StepCount int       `json:"step_count"`
+	// Cost is the total cost of the steps and WaterUsed the amount of water
+	// taken from the lake, see WaterUsed. Both are nil until Weigh sets them,
+	// so they are left out unless costs were asked for, even when zero.
+	Cost      *int   `json:"cost,omitempty"`
+	WaterUsed *int   `json:"water_used,omitempty"`
+	Steps     []Step `json:"steps"`
+}
+
+// NewResult builds the Result for a puzzle, the solution is ignored if it is
+// not solvable.
+func NewResult(initial State, goal Goal, solution Solution, solvable bool) Result {
+	r := Result{
+		Initial:  initial,
+		Solvable: solvable,
+		Steps:    []Step{},
+	}
+	if spec, ok := SpecOf(goal); ok {
+		r.Goal = &spec
+	}
+	if solvable && solution.Steps != nil {
+		r.StepCount = len(solution.Steps)
+		r.Steps = solution.Steps
+	}
+	return r
+}
+
+// Weigh sets the Cost of the steps with the given costs, and the WaterUsed.
+func (r *Result) Weigh(costs Costs) {
+	cost, water := costs.Total(r.Steps), WaterUsed(r.Initial, r.Steps)
+	r.Cost, r.WaterUsed = &cost, &water
+}
+
+// MultiResult is the generalisation of Result for any number of jugs.
+type MultiResult struct {
+	Initial   MultiState  `json:"initial"`
+	Goal      *GoalSpec   `json:"goal"`
+	Solvable  bool        `json:"solvable"`
+	StepCount int         `json:"step_count"`
+	Steps     []MultiStep `json:"steps"`
+}
+
+// NewMultiResult builds the MultiResult for a puzzle, the solution is ignored
+// if it is not solvable.
+func NewMultiResult(initial MultiState, goal Goal, solution MultiSolution, solvable bool) MultiResult {
+	r := MultiResult{
+		Initial:  initial,
+		Solvable: solvable,
+		Steps:    []MultiStep{},
+	}
+	if spec, ok := SpecOf(goal); ok {
+		r.Goal = &spec
+	}
+	if solvable && solution.Steps != nil {
+		r.StepCount = len(solution.Steps)
+		r.Steps = solution.Steps
+	}
+	return r
+}
@@ -0,0 +1,115 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionJSON(t *testing.T) {
+
+	for _, action := range models.Actions {
+		encoded, err := json.Marshal(action)
+		require.NoError(t, err)
+		assert.Equal(t, `"`+action.ID()+`"`, string(encoded))
+
+		var decoded models.Action
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+		assert.Equal(t, action, decoded)
+	}
+
+	_, err := json.Marshal(models.Action("Drink X"))
+	assert.Error(t, err)
+
+	var decoded models.Action
+	assert.Error(t, json.Unmarshal([]byte(`"drink_x"`), &decoded))
+}
+
+func TestSpecOf(t *testing.T) {
+
+	goals := []models.Goal{
+		models.AnyJug{Z: 3},
+		models.InJug{Jug: models.JugX, Z: 3},
+		models.InJug{Jug: models.JugY, Z: 3},
+		models.Sum{Z: 3},
+		models.Exact{Amounts: []int{2, 3}},
+	}
+	for _, goal := range goals {
+		spec, ok := models.SpecOf(goal)
+		require.True(t, ok, goal.String())
+
+		actual, err := spec.Goal()
+		require.NoError(t, err)
+		assert.Equal(t, goal, actual)
+	}
+
+	_, ok := models.SpecOf(models.InJug{Jug: 2, Z: 3})
+	assert.False(t, ok)
+}
+
+func TestNewResult(t *testing.T) {
+
+	initial := models.State{
+		X: models.Jug{Capacity: 9},
+		Y: models.Jug{Capacity: 3},
+	}
+
+	t.Run("unsolvable puzzles have no steps", func(t *testing.T) {
+		result := models.NewResult(initial, models.AnyJug{Z: 4}, models.Solution{}, false)
+
+		encoded, err := json.Marshal(result)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"initial": {"x": {"capacity": 9, "amount": 0}, "y": {"capacity": 3, "amount": 0}},
+			"goal": {"kind": "either", "targets": [4]},
+			"solvable": false,
+			"step_count": 0,
+			"steps": []
+		}`, string(encoded))
+	})
+
+	t.Run("solvable puzzles count their steps", func(t *testing.T) {
+		solution := models.Solution{Steps: []models.Step{{
+			State:  initial.Apply(models.ActionFillY),
+			Action: models.ActionFillY,
+		}}}
+		result := models.NewResult(initial, models.AnyJug{Z: 3}, solution, true)
+
+		assert.True(t, result.Solvable)
+		assert.Equal(t, 1, result.StepCount)
+		assert.Equal(t, solution.Steps, result.Steps)
+		assert.Nil(t, result.Cost, "costs are only set by Weigh")
+
+		result.Weigh(models.UnitCosts)
+		require.NotNil(t, result.Cost)
+		assert.Equal(t, 1, *result.Cost)
+		assert.Equal(t, 3, *result.WaterUsed)
+
+		result.Weigh(models.Costs{models.ActionFillY: 5})
+		assert.Equal(t, 5, *result.Cost)
+	})
+
+	t.Run("weighed results keep zero costs", func(t *testing.T) {
+		solution := models.Solution{Steps: []models.Step{{
+			State:  initial.Apply(models.ActionFillY),
+			Action: models.ActionFillY,
+		}}}
+		result := models.NewResult(initial, models.AnyJug{Z: 3}, solution, true)
+		result.Weigh(models.Costs{models.ActionFillY: 0})
+
+		encoded, err := json.Marshal(result)
+		require.NoError(t, err)
+		assert.Contains(t, string(encoded), `"cost":0,"water_used":3`)
+
+		result = models.NewResult(initial, models.AnyJug{Z: 0}, models.Solution{Steps: []models.Step{}}, true)
+		result.Weigh(models.UnitCosts)
+
+		encoded, err = json.Marshal(result)
+		require.NoError(t, err)
+		assert.Contains(t, string(encoded), `"cost":0,"water_used":0`)
+	})
+}
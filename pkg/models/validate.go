@@ -0,0 +1,132 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSolution indicates that a solution does not solve its puzzle, see
+// Validate.
+var ErrInvalidSolution = errors.New("invalid solution")
+
+// StepError indicates the first step of a solution which does not follow from
+// the previous one, or the last step if it does not reach the goal.
+//
+// It wraps ErrInvalidSolution.
+type StepError struct {
+	// Step is the index of the step in the solution, starting at 0.
+	Step   int
+	Reason string
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("%s: step %d: %s", ErrInvalidSolution, e.Step, e.Reason)
+}
+
+func (e *StepError) Unwrap() error {
+	return ErrInvalidSolution
+}
+
+// Validate replays the solution from the initial state and returns an error
+// wrapping ErrInvalidSolution if it does not solve the puzzle.
+//
+// Every step must be the state reached by taking its action on the previous
+// state, which means capacities never change, and the last state must reach
+// the goal. A *StepError names the first step that does not.
+func Validate(initial State, goal Goal, solution Solution) error {
+	if err := initial.CheckAmounts(); err != nil {
+		return fmt.Errorf("%w: initial state: %s", ErrInvalidSolution, err)
+	}
+
+	state := initial
+	for i, step := range solution.Steps {
+		if step.Action.ID() == "" {
+			return &StepError{Step: i, Reason: fmt.Sprintf("unknown action %q", string(step.Action))}
+		}
+		if step.State.X.Capacity != state.X.Capacity || step.State.Y.Capacity != state.Y.Capacity {
+			return &StepError{Step: i, Reason: fmt.Sprintf("capacities changed from %s to %s",
+				state.Multi(), step.State.Multi())}
+		}
+		next := state.Apply(step.Action)
+		if step.State != next {
+			return &StepError{Step: i, Reason: fmt.Sprintf("%s from %s leads to %s, not %s",
+				step.Action, state.Multi(), next.Multi(), step.State.Multi())}
+		}
+		state = next
+	}
+
+	if !goal.Reached(state.Jugs()) {
+		if len(solution.Steps) == 0 {
+			return fmt.Errorf("%w: %s is not reached without steps", ErrInvalidSolution, goal)
+		}
+		return &StepError{Step: len(solution.Steps) - 1, Reason: fmt.Sprintf("%s is not reached", goal)}
+	}
+	return nil
+}
+
+// ValidateMulti is the generalisation of Validate for any number of jugs.
+func ValidateMulti(initial MultiState, goal Goal, solution MultiSolution) error {
+	if err := initial.CheckAmounts(); err != nil {
+		return fmt.Errorf("%w: initial state: %s", ErrInvalidSolution, err)
+	}
+
+	state := initial
+	for i, step := range solution.Steps {
+		if !state.valid(step.Move) {
+			return &StepError{Step: i, Reason: fmt.Sprintf("invalid move %+v", step.Move)}
+		}
+		if !sameCapacities(state, step.State) {
+			return &StepError{Step: i, Reason: fmt.Sprintf("capacities changed from %s to %s",
+				state, step.State)}
+		}
+		next := state.Apply(step.Move)
+		if !sameAmounts(next, step.State) {
+			return &StepError{Step: i, Reason: fmt.Sprintf("%s from %s leads to %s, not %s",
+				step.Move, state, next, step.State)}
+		}
+		state = next
+	}
+
+	if !goal.Reached(state.Jugs) {
+		if len(solution.Steps) == 0 {
+			return fmt.Errorf("%w: %s is not reached without steps", ErrInvalidSolution, goal)
+		}
+		return &StepError{Step: len(solution.Steps) - 1, Reason: fmt.Sprintf("%s is not reached", goal)}
+	}
+	return nil
+}
+
+// valid reports whether the move references jugs of the state.
+func (s MultiState) valid(m Move) bool {
+	jug := func(i int) bool {
+		return i >= 0 && i < len(s.Jugs)
+	}
+	switch m.Kind {
+	case MoveFill, MoveEmpty:
+		return jug(m.From)
+	case MovePour:
+		return jug(m.From) && jug(m.To) && m.From != m.To
+	}
+	return false
+}
+
+func sameCapacities(a, b MultiState) bool {
+	if len(a.Jugs) != len(b.Jugs) {
+		return false
+	}
+	for i := range a.Jugs {
+		if a.Jugs[i].Capacity != b.Jugs[i].Capacity {
+			return false
+		}
+	}
+	return true
+}
+
+func sameAmounts(a, b MultiState) bool {
+	for i := range a.Jugs {
+		if a.Jugs[i].Amount != b.Jugs[i].Amount {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+
+	initial := models.State{
+		X: models.Jug{Capacity: 5},
+		Y: models.Jug{Capacity: 3},
+	}
+	// 5, 3, 4: fill X, transfer to Y, empty Y, transfer to Y, fill X, transfer to Y.
+	valid := func() models.Solution {
+		state := initial
+		solution := models.Solution{}
+		for _, action := range []models.Action{
+			models.ActionFillX, models.ActionTransferY, models.ActionEmptyY,
+			models.ActionTransferY, models.ActionFillX, models.ActionTransferY,
+		} {
+			state = state.Apply(action)
+			solution.Steps = append(solution.Steps, models.Step{State: state, Action: action})
+		}
+		return solution
+	}
+
+	t.Run("valid solution", func(t *testing.T) {
+		assert.NoError(t, models.Validate(initial, models.AnyJug{Z: 4}, valid()))
+	})
+
+	t.Run("no steps", func(t *testing.T) {
+		assert.NoError(t, models.Validate(initial, models.AnyJug{Z: 0}, models.Solution{}))
+
+		err := models.Validate(initial, models.AnyJug{Z: 4}, models.Solution{})
+		assert.ErrorIs(t, err, models.ErrInvalidSolution)
+	})
+
+	invalid := []struct {
+		name   string
+		modify func(s *models.Solution)
+		step   int
+	}{
+		{
+			name: "state does not follow the action",
+			modify: func(s *models.Solution) {
+				s.Steps[2].State.Y.Amount = 1
+			},
+			step: 2,
+		},
+		{
+			name: "action does not lead to the state",
+			modify: func(s *models.Solution) {
+				s.Steps[1].Action = models.ActionTransferX
+			},
+			step: 1,
+		},
+		{
+			name: "unknown action",
+			modify: func(s *models.Solution) {
+				s.Steps[3].Action = "Drink X"
+			},
+			step: 3,
+		},
+		{
+			name: "capacities change",
+			modify: func(s *models.Solution) {
+				s.Steps[0].State.X.Capacity = 6
+				s.Steps[0].State.X.Amount = 6
+			},
+			step: 0,
+		},
+		{
+			name: "goal is not reached",
+			modify: func(s *models.Solution) {
+				s.Steps = s.Steps[:4]
+			},
+			step: 3,
+		},
+	}
+	for _, test := range invalid {
+		t.Run(test.name, func(t *testing.T) {
+			solution := valid()
+			test.modify(&solution)
+
+			err := models.Validate(initial, models.AnyJug{Z: 4}, solution)
+			require.ErrorIs(t, err, models.ErrInvalidSolution)
+			var stepErr *models.StepError
+			require.ErrorAs(t, err, &stepErr)
+			assert.Equal(t, test.step, stepErr.Step)
+		})
+	}
+
+	t.Run("invalid initial state", func(t *testing.T) {
+		initial := initial
+		initial.X.Amount = 6
+		err := models.Validate(initial, models.AnyJug{Z: 4}, valid())
+		assert.ErrorIs(t, err, models.ErrInvalidSolution)
+	})
+}
+
+func TestValidateMulti(t *testing.T) {
+
+	initial := models.MultiState{Jugs: []models.Jug{{Capacity: 6}, {Capacity: 9}, {Capacity: 10}}}
+	fill := initial.Apply(models.Fill(2))
+	solution := models.MultiSolution{
+		Steps: []models.MultiStep{
+			{State: fill, Move: models.Fill(2)},
+			{State: fill.Apply(models.Pour(2, 1)), Move: models.Pour(2, 1)},
+		},
+	}
+	assert.NoError(t, models.ValidateMulti(initial, models.AnyJug{Z: 1}, solution))
+
+	err := models.ValidateMulti(initial, models.AnyJug{Z: 2}, solution)
+	var stepErr *models.StepError
+	require.ErrorAs(t, err, &stepErr)
+	assert.Equal(t, 1, stepErr.Step)
+
+	solution.Steps[0].Move = models.Pour(2, 3)
+	err = models.ValidateMulti(initial, models.AnyJug{Z: 1}, solution)
+	require.ErrorAs(t, err, &stepErr)
+	assert.Equal(t, 0, stepErr.Step)
+
+	solution.Steps[0].Move = models.Fill(1)
+	err = models.ValidateMulti(initial, models.AnyJug{Z: 1}, solution)
+	require.ErrorAs(t, err, &stepErr)
+	assert.Equal(t, 0, stepErr.Step)
+}
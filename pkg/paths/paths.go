@@ -0,0 +1,184 @@
+// Package paths enumerates every distinct solution to the water jug puzzle,
+// not only the shortest.
+//
+// A solution is a simple path in the graph described in package bfs: no state
+// is visited twice, and it ends the first time the goal is reached.
+//
+// Let's take x = 3, y = 2, z = 1 as an example, the solutions of up to 4
+// steps ranked by length are:
+//
+// Fill X -> (3, 0) -> Transfer to Y -> (1, 2)
+// Fill Y -> (0, 2) -> Fill X -> (3, 2) -> Empty Y -> (3, 0) -> Transfer to Y -> (1, 2)
+// Fill Y -> (0, 2) -> Transfer to X -> (2, 0) -> Fill X -> (3, 0) -> Transfer to Y -> (1, 2)
+// Fill Y -> (0, 2) -> Transfer to X -> (2, 0) -> Fill Y -> (2, 2) -> Transfer to X -> (3, 1)
+//
+// There may be a huge amount of them, so they are listed lazily through an
+// Iterator. Solutions are found by a depth first search bounded by a length,
+// which grows by one once every solution of that length is listed. Paths
+// shorter than the bound are walked again for every length, but memory stays
+// bounded by the length of the solutions.
+package paths
+
+import (
+	"errors"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// frame is a state in the path being searched, along with the action that
+// reached it and the index of the next action to take from it.
+type frame struct {
+	state  models.State
+	action models.Action
+	next   int
+}
+
+// Iterator lists the solutions to a puzzle ranked by length, solutions with
+// the same length are listed in no particular order.
+//
+//	it, err := paths.Enumerate(state, goal, 0)
+//	for it.Next() {
+//		solution := it.Solution()
+//	}
+type Iterator struct {
+	start     models.State
+	goal      models.Goal
+	maxLength int
+
+	// length is the length of the solutions being searched.
+	length int
+	// cutOff reports whether a path was cut off by the length, otherwise
+	// there are no longer solutions.
+	cutOff   bool
+	stack    []frame
+	onPath   map[models.State]bool
+	solution models.Solution
+	done     bool
+}
+
+// Enumerate returns an Iterator over the solutions to the puzzle, starting
+// from the amounts in the baseState, of at most maxLength steps. There is no
+// limit if maxLength is zero.
+//
+// Solutions are listed ranked by length, so the first k are the k shortest.
+//
+// A *models.AmountError is returned if the starting amounts are invalid.
+func Enumerate(baseState models.State, goal models.Goal, maxLength int) (*Iterator, error) {
+
+	if baseState.Y.Capacity <= 0 || baseState.X.Capacity <= 0 {
+		return nil, errors.New("both x and y must be positive")
+	}
+	if err := baseState.CheckAmounts(); err != nil {
+		return nil, err
+	}
+	if err := goal.Validate(baseState.Jugs()); err != nil {
+		return nil, err
+	}
+	if maxLength < 0 {
+		return nil, errors.New("the maximum length must be zero or greater")
+	}
+
+	return &Iterator{
+		start:     baseState,
+		goal:      goal,
+		maxLength: maxLength,
+		onPath:    map[models.State]bool{},
+	}, nil
+}
+
+// Shortest returns the k shortest solutions to the puzzle, fewer if there are
+// not as many. See Enumerate.
+func Shortest(baseState models.State, goal models.Goal, k int) ([]models.Solution, error) {
+
+	it, err := Enumerate(baseState, goal, 0)
+	if err != nil {
+		return nil, err
+	}
+	var solutions []models.Solution
+	for len(solutions) < k && it.Next() {
+		solutions = append(solutions, it.Solution())
+	}
+	return solutions, nil
+}
+
+// Next finds the next solution, which is then returned by Solution. It returns
+// false once there are no more solutions.
+func (it *Iterator) Next() bool {
+
+	if it.done {
+		return false
+	}
+	// Every path from a start reaching the goal reaches it again.
+	if it.goal.Reached(it.start.Jugs()) {
+		it.solution = models.Solution{}
+		it.done = true
+		return true
+	}
+
+	for {
+		if len(it.stack) == 0 && !it.deepen() {
+			it.done = true
+			return false
+		}
+
+		top := &it.stack[len(it.stack)-1]
+		if top.next == len(models.Actions) {
+			delete(it.onPath, top.state)
+			it.stack = it.stack[:len(it.stack)-1]
+			continue
+		}
+		action := models.Actions[top.next]
+		top.next++
+
+		next := top.state.Apply(action)
+		if it.onPath[next] {
+			continue
+		}
+		// The stack holds the start, so its length is the amount of steps.
+		steps := len(it.stack)
+		if it.goal.Reached(next.Jugs()) {
+			if steps == it.length {
+				it.solution = it.path(next, action)
+				return true
+			}
+			continue
+		}
+		if steps == it.length {
+			it.cutOff = true
+			continue
+		}
+		it.stack = append(it.stack, frame{state: next, action: action})
+		it.onPath[next] = true
+	}
+}
+
+// Solution returns the solution found by the last call to Next.
+func (it *Iterator) Solution() models.Solution {
+	return it.solution
+}
+
+// deepen starts searching for solutions one step longer, it returns false if
+// there cannot be any.
+func (it *Iterator) deepen() bool {
+	if it.length > 0 && !it.cutOff {
+		return false
+	}
+	if it.maxLength > 0 && it.length == it.maxLength {
+		return false
+	}
+	it.length++
+	it.cutOff = false
+	it.stack = append(it.stack, frame{state: it.start})
+	it.onPath[it.start] = true
+	return true
+}
+
+// path builds the Solution from the stack, ending with the last action.
+func (it *Iterator) path(end models.State, action models.Action) models.Solution {
+	steps := make([]models.Step, 0, len(it.stack))
+	for _, f := range it.stack[1:] {
+		steps = append(steps, models.Step{State: f.state, Action: f.action})
+	}
+	steps = append(steps, models.Step{State: end, Action: action})
+	return models.Solution{Steps: steps}
+}
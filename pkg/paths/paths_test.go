@@ -0,0 +1,124 @@
+package paths_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/paths"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvertest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnumerate(t *testing.T) {
+
+	t.Run("every solution of 3, 2, 1", func(t *testing.T) {
+		it, err := paths.Enumerate(solvertest.NewState(3, 2), models.AnyJug{Z: 1}, 0)
+		require.NoError(t, err)
+
+		var lengths []int
+		for it.Next() {
+			lengths = append(lengths, len(it.Solution().Steps))
+		}
+		assert.Equal(t, []int{2, 4, 4, 4, 6, 6}, lengths)
+		assert.False(t, it.Next(), "the iterator stays done")
+	})
+
+	t.Run("maximum length", func(t *testing.T) {
+		it, err := paths.Enumerate(solvertest.NewState(3, 2), models.AnyJug{Z: 1}, 3)
+		require.NoError(t, err)
+
+		require.True(t, it.Next())
+		assert.Len(t, it.Solution().Steps, 2)
+		assert.False(t, it.Next())
+	})
+
+	t.Run("z already measured has a single solution", func(t *testing.T) {
+		it, err := paths.Enumerate(solvertest.NewState(3, 2), models.AnyJug{Z: 0}, 0)
+		require.NoError(t, err)
+
+		require.True(t, it.Next())
+		assert.Empty(t, it.Solution().Steps)
+		assert.False(t, it.Next())
+	})
+
+	t.Run("no solution", func(t *testing.T) {
+		it, err := paths.Enumerate(solvertest.NewState(4, 2), models.AnyJug{Z: 3}, 0)
+		require.NoError(t, err)
+		assert.False(t, it.Next())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := paths.Enumerate(solvertest.NewState(3, 2), models.AnyJug{Z: 4}, 0)
+		assert.Error(t, err)
+
+		_, err = paths.Enumerate(solvertest.NewState(3, 0), models.AnyJug{Z: 1}, 0)
+		assert.Error(t, err)
+
+		_, err = paths.Enumerate(solvertest.NewState(3, 2), models.AnyJug{Z: 1}, -1)
+		assert.Error(t, err)
+	})
+}
+
+// TestSolutions checks every solution is valid, distinct, simple and ranked,
+// the first one being as short as the one found by bfs.
+func TestSolutions(t *testing.T) {
+
+	for x := 1; x <= 5; x++ {
+		for y := 1; y <= 5; y++ {
+			for z := 0; z <= x || z <= y; z++ {
+				t.Run(fmt.Sprintf("x=%d, y=%d, z=%d", x, y, z), func(t *testing.T) {
+					goal := models.AnyJug{Z: z}
+					shortest, bfsErr := bfs.Solve(solvertest.NewState(x, y), z)
+
+					it, err := paths.Enumerate(solvertest.NewState(x, y), goal, 8)
+					require.NoError(t, err)
+
+					seen := map[string]bool{}
+					previous := 0
+					for it.Next() {
+						solution := it.Solution()
+						require.NoError(t, models.Validate(solvertest.NewState(x, y), goal, solution))
+						if len(seen) == 0 {
+							require.NoError(t, bfsErr)
+							assert.Len(t, solution.Steps, len(shortest.Steps))
+						}
+						assert.GreaterOrEqual(t, len(solution.Steps), previous)
+						previous = len(solution.Steps)
+
+						key := fmt.Sprint(solution.Steps)
+						assert.False(t, seen[key], "repeated solution")
+						seen[key] = true
+
+						states := map[models.State]bool{solvertest.NewState(x, y): true}
+						for i, step := range solution.Steps {
+							assert.False(t, states[step.State], "state visited twice")
+							states[step.State] = true
+							if i < len(solution.Steps)-1 {
+								assert.False(t, goal.Reached(step.State.Jugs()), "goal reached before the end")
+							}
+						}
+					}
+					if bfsErr != nil {
+						assert.Empty(t, seen)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestShortest(t *testing.T) {
+
+	solutions, err := paths.Shortest(solvertest.NewState(5, 3), models.AnyJug{Z: 4}, 3)
+	require.NoError(t, err)
+	require.Len(t, solutions, 3)
+	assert.Len(t, solutions[0].Steps, 6)
+
+	solutions, err = paths.Shortest(solvertest.NewState(3, 2), models.AnyJug{Z: 1}, 100)
+	require.NoError(t, err)
+	assert.Len(t, solutions, 6, "fewer if there are not as many")
+}
@@ -0,0 +1,190 @@
+// Package server exposes the water jug riddle solvers through an HTTP API.
+//
+// Puzzles are checked and solved the same way the CLI does, see app.App.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// maxBodySize bounds the POST /solve request body, a puzzle is tiny.
+const maxBodySize = 1 << 16
+
+// Error is the body of every response but the solutions.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Configuration is the base configuration for instantiating the API handler.
+type Configuration struct {
+	// Solver solves the models.GoalAny goal, it is optional if GoalSolver is
+	// set. See app.Solver for more information.
+	Solver app.Solver
+	// GoalSolver solves every other goal, requests for other goals are
+	// rejected if it is nil. See app.GoalSolver for more information.
+	GoalSolver app.GoalSolver
+	// Timeout bounds how long solving each puzzle may take, there is no
+	// limit if it is zero. See app.Configuration.
+	Timeout time.Duration
+}
+
+// New instantiates the API handler, it serves:
+//
+//	GET  /health  always 200, as long as the server is up.
+//	GET  /solve   the puzzle in the query, as in /solve?x=5&y=4&z=3.
+//	POST /solve   the puzzle in the JSON body, see app.Puzzle.
+//
+// GET /solve takes the same fields as query parameters, in which case z may
+// have several comma separated targets, as in z=4,0.
+//
+// Solutions are written as a models.Result with a 200 status code, puzzles
+// without a solution are also written as a models.Result but with a 422
+// status code. Invalid puzzles get a 400 status code and an Error, puzzles
+// which exceed the timeout get a 503 status code and an Error.
+func New(conf Configuration) (http.Handler, error) {
+	a, err := app.New(app.Configuration{
+		Output:     io.Discard,
+		Input:      strings.NewReader(""),
+		Silent:     true,
+		Solver:     conf.Solver,
+		GoalSolver: conf.GoalSolver,
+		Timeout:    conf.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := server{app: &a}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.health)
+	mux.HandleFunc("/solve", s.solve)
+	return mux, nil
+}
+
+type server struct {
+	app *app.App
+}
+
+func (s server) health(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, http.MethodGet, http.MethodHead)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s server) solve(w http.ResponseWriter, r *http.Request) {
+	var (
+		puzzle app.Puzzle
+		err    error
+	)
+	switch r.Method {
+	case http.MethodGet:
+		puzzle, err = queryPuzzle(r)
+	case http.MethodPost:
+		puzzle, err = bodyPuzzle(w, r)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Error{Error: err.Error()})
+		return
+	}
+
+	var result models.Result
+	state, goal, err := puzzle.Build()
+	if err == nil {
+		result, err = s.app.SolveContext(r.Context(), state, goal)
+	}
+	if errors.Is(err, app.ErrInvalidParameters) {
+		writeJSON(w, http.StatusBadRequest, Error{Error: err.Error()})
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		writeJSON(w, http.StatusServiceUnavailable, Error{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, Error{Error: err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if !result.Solvable {
+		status = http.StatusUnprocessableEntity
+	}
+	writeJSON(w, status, result)
+}
+
+// queryPuzzle reads the app.Puzzle from the query parameters.
+func queryPuzzle(r *http.Request) (app.Puzzle, error) {
+	query := r.URL.Query()
+	number := func(name string) (int, error) {
+		value := query.Get(name)
+		if value == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q, a number was expected", name, value)
+		}
+		return n, nil
+	}
+
+	var (
+		puzzle app.Puzzle
+		err    error
+	)
+	for name, n := range map[string]*int{"x": &puzzle.X, "y": &puzzle.Y, "wx": &puzzle.WX, "wy": &puzzle.WY} {
+		*n, err = number(name)
+		if err != nil {
+			return app.Puzzle{}, err
+		}
+	}
+	puzzle.Goal = models.GoalKind(query.Get("goal"))
+
+	if z := query.Get("z"); z != "" {
+		puzzle.Targets, err = app.ParseTargets(z)
+		if err != nil {
+			return app.Puzzle{}, err
+		}
+	}
+	return puzzle, nil
+}
+
+// bodyPuzzle reads the app.Puzzle from the JSON body.
+func bodyPuzzle(w http.ResponseWriter, r *http.Request) (app.Puzzle, error) {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+
+	var puzzle app.Puzzle
+	err := decoder.Decode(&puzzle)
+	if err != nil {
+		return app.Puzzle{}, fmt.Errorf("invalid body: %w", err)
+	}
+	return puzzle, nil
+}
+
+func methodNotAllowed(w http.ResponseWriter, methods ...string) {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeJSON(w, http.StatusMethodNotAllowed, Error{Error: "method not allowed"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	// The status is already written, there is nothing left to do on failure.
+	_ = json.NewEncoder(w).Encode(body)
+}
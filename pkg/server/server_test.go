@@ -0,0 +1,166 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/iterative"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/server"
+)
+
+func TestSolve(t *testing.T) {
+
+	handler, err := server.New(server.Configuration{
+		Solver:     app.SolverFun(iterative.Solve),
+		GoalSolver: app.GoalSolverFun(bfs.SolveGoal),
+	})
+	require.NoError(t, err)
+
+	t.Run("get", func(t *testing.T) {
+		response := serve(handler, http.MethodGet, "/solve?x=5&y=4&z=3", "")
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+		assert.NotContains(t, response.Body.String(), "cost", "costs are only written when asked for")
+
+		result := decodeResult(t, response)
+		assert.True(t, result.Solvable)
+		assert.Equal(t, 4, result.StepCount)
+		assert.Equal(t, &models.GoalSpec{Kind: models.GoalAny, Targets: []int{3}}, result.Goal)
+	})
+
+	t.Run("post", func(t *testing.T) {
+		response := serve(handler, http.MethodPost, "/solve", `{"x":5,"y":4,"z":3}`)
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, 4, decodeResult(t, response).StepCount)
+	})
+
+	t.Run("goals and starting amounts", func(t *testing.T) {
+		response := serve(handler, http.MethodGet, "/solve?x=5&y=4&z=4,0&goal=exact&wx=2", "")
+		require.Equal(t, http.StatusOK, response.Code)
+		result := decodeResult(t, response)
+		assert.Equal(t, 2, result.Initial.X.Amount)
+		assert.Equal(t, models.State{
+			X: models.Jug{Capacity: 5, Amount: 4},
+			Y: models.Jug{Capacity: 4, Amount: 0},
+		}, result.Steps[len(result.Steps)-1].State)
+
+		response = serve(handler, http.MethodPost, "/solve", `{"x":5,"y":4,"targets":[4,0],"goal":"exact"}`)
+		require.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("no solution", func(t *testing.T) {
+		response := serve(handler, http.MethodGet, "/solve?x=4&y=2&z=3", "")
+		require.Equal(t, http.StatusUnprocessableEntity, response.Code)
+		result := decodeResult(t, response)
+		assert.False(t, result.Solvable)
+		assert.Empty(t, result.Steps)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		invalid := []struct {
+			method string
+			target string
+			body   string
+		}{
+			{http.MethodGet, "/solve?x=5&y=4&z=6", ""},
+			{http.MethodGet, "/solve?x=0&y=4&z=3", ""},
+			{http.MethodGet, "/solve?x=5&y=4", ""},
+			{http.MethodGet, "/solve?x=five&y=4&z=3", ""},
+			{http.MethodGet, "/solve?x=5&y=4&z=3&wx=6", ""},
+			{http.MethodGet, "/solve?x=5&y=4&z=3&goal=half", ""},
+			{http.MethodPost, "/solve", `{"x":5,"y":4,"z":-1}`},
+			{http.MethodPost, "/solve", `{"x":5,"y":4,"w":3}`},
+			{http.MethodPost, "/solve", `{"x":5,`},
+		}
+		for _, test := range invalid {
+			response := serve(handler, test.method, test.target, test.body)
+			assert.Equal(t, http.StatusBadRequest, response.Code, test.target+test.body)
+
+			var body server.Error
+			require.NoError(t, json.NewDecoder(response.Body).Decode(&body))
+			assert.NotEmpty(t, body.Error)
+		}
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		response := serve(handler, http.MethodDelete, "/solve", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, response.Code)
+		assert.Equal(t, "GET, POST", response.Header().Get("Allow"))
+	})
+
+	t.Run("goals without a goal solver", func(t *testing.T) {
+		handler, err := server.New(server.Configuration{
+			Solver: app.SolverFun(iterative.Solve),
+		})
+		require.NoError(t, err)
+
+		response := serve(handler, http.MethodGet, "/solve?x=5&y=4&z=3&goal=sum", "")
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		handler, err := server.New(server.Configuration{
+			Solver: app.ContextSolverFun(func(ctx context.Context, state models.State, z int) (models.Solution, error) {
+				<-ctx.Done()
+				return models.Solution{}, ctx.Err()
+			}),
+			Timeout: 10 * time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		response := serve(handler, http.MethodGet, "/solve?x=5&y=4&z=3", "")
+		assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	})
+
+	t.Run("solver failure", func(t *testing.T) {
+		handler, err := server.New(server.Configuration{
+			Solver: app.SolverFun(func(state models.State, z int) (models.Solution, error) {
+				return models.Solution{}, assert.AnError
+			}),
+		})
+		require.NoError(t, err)
+
+		response := serve(handler, http.MethodGet, "/solve?x=5&y=4&z=3", "")
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+	})
+}
+
+func TestHealth(t *testing.T) {
+
+	handler, err := server.New(server.Configuration{
+		Solver: app.SolverFun(iterative.Solve),
+	})
+	require.NoError(t, err)
+
+	response := serve(handler, http.MethodGet, "/health", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, response.Body.String())
+}
+
+func TestNew(t *testing.T) {
+	_, err := server.New(server.Configuration{})
+	assert.Error(t, err)
+}
+
+func serve(handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return response
+}
+
+func decodeResult(t *testing.T, response *httptest.ResponseRecorder) models.Result {
+	var result models.Result
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&result))
+	return result
+}
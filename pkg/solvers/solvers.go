@@ -0,0 +1,96 @@
+// Package solvers is a registry of the solvers available by name, such as for
+// picking one from the command line.
+//
+// Solver packages register themselves when imported, so a program only needs
+// to import a package to offer its solver. Solvers are registered as plain
+// functions, so solver packages do not depend on the app package, and
+// programs adapt them, as with app.ContextSolverFun:
+//
+//	import _ "github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+//
+//	registration, ok := solvers.Lookup("bfs")
+package solvers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// SolveFunc solves the water jugs riddle, measuring z in either jug. It must
+// return models.ErrNoSolution if there is no solution.
+type SolveFunc func(ctx context.Context, state models.State, z int) (models.Solution, error)
+
+// SolveGoalFunc solves the puzzle for any goal, it must return an error
+// wrapping models.ErrUnsupported for goals it cannot reach.
+type SolveGoalFunc func(ctx context.Context, state models.State, goal models.Goal) (models.Solution, error)
+
+// SolveMultiFunc solves the riddle for any number of jugs.
+type SolveMultiFunc func(ctx context.Context, state models.MultiState, z int) (models.MultiSolution, error)
+
+// Registration describes a solver, any of the functions but Solve may be nil
+// if the kind of puzzle is not supported.
+type Registration struct {
+	// Name is the unique name the solver is looked up by.
+	Name string
+	// Description is a single line summary of the strategy.
+	Description string
+	// EmptyOnly is set by solvers which only solve puzzles starting with
+	// empty jugs, returning models.ErrUnsupported otherwise.
+	EmptyOnly  bool
+	Solve      SolveFunc
+	SolveGoal  SolveGoalFunc
+	SolveMulti SolveMultiFunc
+}
+
+var (
+	mu            sync.RWMutex
+	registrations = map[string]Registration{}
+)
+
+// Register makes the solver available by its name. It panics if the name is
+// empty or already registered, or if Solve is nil, as it is meant to be
+// called from init functions.
+func Register(r Registration) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if r.Name == "" {
+		panic("solvers: name cannot be empty")
+	}
+	if r.Solve == nil {
+		panic(fmt.Sprintf("solvers: solver %q cannot be nil", r.Name))
+	}
+	if _, ok := registrations[r.Name]; ok {
+		panic(fmt.Sprintf("solvers: solver %q registered twice", r.Name))
+	}
+	registrations[r.Name] = r
+}
+
+// Lookup returns the solver registered with the name, ok is false if there is
+// none.
+func Lookup(name string) (r Registration, ok bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	r, ok = registrations[name]
+	return r, ok
+}
+
+// List returns every registered solver, sorted by name.
+func List() []Registration {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	list := make([]Registration, 0, len(registrations))
+	for _, r := range registrations {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
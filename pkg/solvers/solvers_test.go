@@ -0,0 +1,69 @@
+package solvers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	_ "github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	_ "github.com/nacho692/live-free-or-die-jugging/pkg/dijkstra"
+	_ "github.com/nacho692/live-free-or-die-jugging/pkg/euclid"
+	_ "github.com/nacho692/live-free-or-die-jugging/pkg/iterative"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvers"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvertest"
+)
+
+func TestBuiltIn(t *testing.T) {
+
+	for _, name := range []string{"bfs", "dijkstra", "euclid", "iterative"} {
+		t.Run(name, func(t *testing.T) {
+			r, ok := solvers.Lookup(name)
+			require.True(t, ok)
+			assert.Equal(t, name, r.Name)
+			assert.NotEmpty(t, r.Description)
+			solvertest.TestSolver(t, app.ContextSolverFun(r.Solve))
+		})
+	}
+
+	r, _ := solvers.Lookup("bfs")
+	assert.NotNil(t, r.SolveGoal)
+	assert.NotNil(t, r.SolveMulti)
+	r, _ = solvers.Lookup("euclid")
+	assert.Nil(t, r.SolveGoal, "closed form only measures z in either jug")
+}
+
+func TestRegister(t *testing.T) {
+
+	solve := func(ctx context.Context, state models.State, z int) (models.Solution, error) {
+		return models.Solution{}, models.ErrNoSolution
+	}
+	solvers.Register(solvers.Registration{Name: "test", Description: "never solves", Solve: solve})
+
+	r, ok := solvers.Lookup("test")
+	require.True(t, ok)
+	assert.Equal(t, "never solves", r.Description)
+	assert.Nil(t, r.SolveGoal)
+
+	_, ok = solvers.Lookup("unknown")
+	assert.False(t, ok)
+
+	var names []string
+	for _, r := range solvers.List() {
+		names = append(names, r.Name)
+	}
+	assert.Equal(t, []string{"bfs", "dijkstra", "euclid", "iterative", "test"}, names)
+
+	assert.Panics(t, func() {
+		solvers.Register(solvers.Registration{Name: "test", Solve: solve})
+	}, "names are unique")
+	assert.Panics(t, func() {
+		solvers.Register(solvers.Registration{Solve: solve})
+	}, "names cannot be empty")
+	assert.Panics(t, func() {
+		solvers.Register(solvers.Registration{Name: "nil"})
+	}, "solvers cannot be nil")
+}
@@ -0,0 +1,149 @@
+// Package solvertest checks that app.Solver implementations honor its
+// contract, it is meant to be used from the solvers tests:
+//
+//	func TestConformance(t *testing.T) {
+//		solvertest.TestSolver(t, app.SolverFun(Solve))
+//	}
+//
+//	func FuzzSolve(f *testing.F) {
+//		solvertest.FuzzSolver(f, app.SolverFun(Solve))
+//	}
+package solvertest
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+const (
+	// exhaustive is the capacity up to which every puzzle is checked.
+	exhaustive = 12
+	// random is the number of random puzzles checked, up to MaxCapacity.
+	random = 500
+	// seed makes the random puzzles the same on every run, so failures can be
+	// reproduced.
+	seed = 692
+)
+
+// MaxCapacity bounds the capacities of the random and fuzzed puzzles, so
+// exhaustive solvers finish in a reasonable time.
+const MaxCapacity = 200
+
+// NewState returns the state of the empty jugs x and y, the start of most
+// puzzles in the solvers tests.
+func NewState(x, y int) models.State {
+	return models.State{
+		X: models.Jug{Capacity: x},
+		Y: models.Jug{Capacity: y},
+	}
+}
+
+// Check solves the puzzle for the jugs x and y, starting empty, and z.
+//
+// A solution must be found and replay step by step if the greatest common
+// divisor of x and y divides z, otherwise models.ErrNoSolution is expected.
+//
+// The puzzle must be valid: x and y positive and z between 0 and the largest
+// of them.
+func Check(solver app.Solver, x, y, z int) error {
+	if x <= 0 || y <= 0 || z < 0 || z > max(x, y) {
+		return fmt.Errorf("x=%d, y=%d, z=%d: invalid puzzle", x, y, z)
+	}
+	state := NewState(x, y)
+	solvable := z%gcd(x, y) == 0
+
+	solution, err := solver.Solve(state, z)
+	if !solvable {
+		if !errors.Is(err, models.ErrNoSolution) {
+			return fmt.Errorf("x=%d, y=%d, z=%d: expected %q, got %v", x, y, z, models.ErrNoSolution, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("x=%d, y=%d, z=%d: expected a solution, got %v", x, y, z, err)
+	}
+	err = models.Validate(state, models.AnyJug{Z: z}, solution)
+	if err != nil {
+		return fmt.Errorf("x=%d, y=%d, z=%d: %w", x, y, z, err)
+	}
+	return nil
+}
+
+// TestSolver checks every puzzle with small capacities and random puzzles up
+// to MaxCapacity, see Check.
+func TestSolver(t *testing.T, solver app.Solver) {
+	t.Helper()
+
+	t.Run("small puzzles", func(t *testing.T) {
+		for x := 1; x <= exhaustive; x++ {
+			for y := 1; y <= exhaustive; y++ {
+				for z := 0; z <= max(x, y); z++ {
+					if err := Check(solver, x, y, z); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("random puzzles", func(t *testing.T) {
+		r := rand.New(rand.NewSource(seed))
+		for i := 0; i < random; i++ {
+			x, y := 1+r.Intn(MaxCapacity), 1+r.Intn(MaxCapacity)
+			// Half of them are multiples of the gcd, so they have a solution.
+			z := r.Intn(max(x, y) + 1)
+			if r.Intn(2) == 0 {
+				z = r.Intn(max(x, y)/gcd(x, y)+1) * gcd(x, y)
+			}
+			if err := Check(solver, x, y, z); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+}
+
+// FuzzSolver fuzzes the solver with any puzzle up to MaxCapacity, see Check.
+//
+// Fuzzed values are brought into range, so any input makes a valid puzzle.
+func FuzzSolver(f *testing.F, solver app.Solver) {
+	f.Add(5, 4, 3)
+	f.Add(3, 5, 4)
+	f.Add(2, 6, 5)
+	f.Add(9, 3, 4)
+	f.Add(7, 7, 0)
+	f.Add(1, 1, 1)
+
+	f.Fuzz(func(t *testing.T, x, y, z int) {
+		x, y = 1+abs(x%MaxCapacity), 1+abs(y%MaxCapacity)
+		z = abs(z % (max(x, y) + 1))
+		if err := Check(solver, x, y, z); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,35 @@
+package solvertest_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/iterative"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvertest"
+)
+
+func TestCheck(t *testing.T) {
+
+	solver := app.SolverFun(iterative.Solve)
+	assert.NoError(t, solvertest.Check(solver, 5, 4, 3))
+	assert.NoError(t, solvertest.Check(solver, 9, 3, 4), "no solution is expected")
+	assert.Error(t, solvertest.Check(solver, 5, 4, 6), "the puzzle is not valid")
+
+	giveUp := app.SolverFun(func(state models.State, z int) (models.Solution, error) {
+		return models.Solution{}, models.ErrNoSolution
+	})
+	assert.Error(t, solvertest.Check(giveUp, 5, 4, 3), "a solution exists")
+
+	fillX := app.SolverFun(func(state models.State, z int) (models.Solution, error) {
+		return models.Solution{
+			Steps: []models.Step{
+				{State: state.Apply(models.ActionFillX), Action: models.ActionFillX},
+			},
+		}, nil
+	})
+	assert.Error(t, solvertest.Check(fillX, 5, 4, 3), "the solution does not reach z")
+	assert.Error(t, solvertest.Check(fillX, 9, 3, 4), "no solution is expected")
+}
@@ -0,0 +1,184 @@
+package table
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Format is the format a Table is written in.
+type Format string
+
+const (
+	// FormatCSV writes a header and a row for each solvable puzzle, as in
+	// "x,y,z,steps". Puzzles missing from it are unsolvable.
+	FormatCSV Format = "csv"
+	// FormatBinary writes a magic number and Max, then the steps of every
+	// puzzle plus one, 0 being unsolvable, ordered by x, y and z. Every
+	// number is an unsigned varint, see encoding/binary.
+	FormatBinary Format = "binary"
+)
+
+// Formats lists every format.
+var Formats = []Format{FormatCSV, FormatBinary}
+
+// magic starts every FormatBinary table, the last byte is the version.
+const magic = "WJT\x01"
+
+// maxMax bounds the Max of the tables read, so a corrupt one does not exhaust
+// the memory.
+const maxMax = 1 << 12
+
+var csvHeader = []string{"x", "y", "z", "steps"}
+
+// Write writes the table in the format.
+func (t *Table) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatCSV:
+		return t.writeCSV(w)
+	case FormatBinary:
+		return t.writeBinary(w)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// Read reads a table written in the format.
+func Read(r io.Reader, format Format) (*Table, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatBinary:
+		return readBinary(r)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+func (t *Table) writeCSV(w io.Writer) error {
+
+	output := csv.NewWriter(w)
+	if err := output.Write(csvHeader); err != nil {
+		return err
+	}
+	for x := 1; x <= t.max; x++ {
+		for y := 1; y <= t.max; y++ {
+			offset := t.offset(x, y)
+			for z := 0; z <= maxInt(x, y); z++ {
+				steps := t.steps[offset+z]
+				if steps == unsolvable {
+					continue
+				}
+				err := output.Write([]string{
+					strconv.Itoa(x), strconv.Itoa(y), strconv.Itoa(z), strconv.Itoa(steps),
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	output.Flush()
+	return output.Error()
+}
+
+// readCSV infers Max from the largest capacity, z = 0 is always solvable so
+// every pair of capacities has a row.
+func readCSV(r io.Reader) (*Table, error) {
+
+	input := csv.NewReader(r)
+	input.FieldsPerRecord = len(csvHeader)
+	input.ReuseRecord = true
+	if _, err := input.Read(); err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	var rows [][4]int
+	max := 0
+	for {
+		record, err := input.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var row [4]int
+		for i, field := range record {
+			row[i], err = strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q", csvHeader[i], field)
+			}
+		}
+		if row[0] < 1 || row[1] < 1 || row[0] > maxMax || row[1] > maxMax {
+			return nil, fmt.Errorf("invalid capacities x=%d, y=%d", row[0], row[1])
+		}
+		if row[2] < 0 || row[2] > maxInt(row[0], row[1]) || row[3] < 0 {
+			return nil, fmt.Errorf("invalid row x=%d, y=%d, z=%d, steps=%d", row[0], row[1], row[2], row[3])
+		}
+		max = maxInt(max, maxInt(row[0], row[1]))
+		rows = append(rows, row)
+	}
+	if max == 0 {
+		return nil, errors.New("empty table")
+	}
+
+	t := newTable(max)
+	for _, row := range rows {
+		t.steps[t.offset(row[0], row[1])+row[2]] = row[3]
+	}
+	return t, nil
+}
+
+func (t *Table) writeBinary(w io.Writer) error {
+
+	output := bufio.NewWriter(w)
+	buf := make([]byte, binary.MaxVarintLen64)
+	if _, err := output.WriteString(magic); err != nil {
+		return err
+	}
+	if _, err := output.Write(buf[:binary.PutUvarint(buf, uint64(t.max))]); err != nil {
+		return err
+	}
+	for _, steps := range t.steps {
+		if _, err := output.Write(buf[:binary.PutUvarint(buf, uint64(steps+1))]); err != nil {
+			return err
+		}
+	}
+	return output.Flush()
+}
+
+func readBinary(r io.Reader) (*Table, error) {
+
+	input := bufio.NewReader(r)
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(input, header); err != nil || string(header) != magic {
+		return nil, errors.New("not a binary table")
+	}
+	max, err := binary.ReadUvarint(input)
+	if err != nil {
+		return nil, fmt.Errorf("reading max: %w", err)
+	}
+	if max < 1 || max > maxMax {
+		return nil, fmt.Errorf("invalid max %d", max)
+	}
+
+	t := newTable(int(max))
+	for i := range t.steps {
+		steps, err := binary.ReadUvarint(input)
+		if err != nil {
+			return nil, fmt.Errorf("reading steps: %w", err)
+		}
+		if steps > math.MaxInt32 {
+			return nil, fmt.Errorf("invalid steps %d", steps)
+		}
+		t.steps[i] = int(steps) - 1
+	}
+	if _, err := input.ReadByte(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after the table")
+	}
+	return t, nil
+}
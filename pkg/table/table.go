@@ -0,0 +1,193 @@
+// Package table precomputes the answer to every puzzle with capacities up to
+// a limit, so solvability and step counts are looked up instead of solved.
+//
+// The table holds, for every x and y from 1 to Max and every z from 0 to
+// max(x, y), whether z can be measured starting from empty jugs and the amount
+// of steps the solver took. It is written either as CSV, a row for each
+// solvable puzzle, or in a compact binary format, see Format.
+package table
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+)
+
+// ErrOutOfRange indicates that the puzzle is not in the table.
+var ErrOutOfRange = errors.New("puzzle out of the table range")
+
+// unsolvable marks the puzzles without a solution in Table.steps.
+const unsolvable = -1
+
+// Answer is what the table knows about a puzzle.
+type Answer struct {
+	Solvable bool `json:"solvable"`
+	// Steps is the amount of steps of the solution, 0 if there is none.
+	Steps int `json:"steps"`
+}
+
+// Table holds the Answer of every puzzle up to Max, see Lookup.
+type Table struct {
+	max int
+	// offsets indexes the first z of each (x, y) pair in steps.
+	offsets []int
+	steps   []int
+}
+
+// Configuration is the base configuration for generating a Table.
+type Configuration struct {
+	// Max is the largest capacity in the table, it must be positive.
+	Max int
+	// Solver solves every puzzle, its solutions are expected to start from
+	// empty jugs.
+	Solver app.Solver
+	// Workers bounds the capacity pairs being solved at the same time, the
+	// number of CPUs is used by default.
+	Workers int
+}
+
+// Generate solves every puzzle up to conf.Max, solving capacity pairs in
+// parallel. Generation stops with an error wrapping ctx.Err() once ctx is
+// done.
+//
+// Any error other than models.ErrNoSolution stops the generation, as the
+// table would be incomplete.
+func Generate(ctx context.Context, conf Configuration) (*Table, error) {
+
+	if conf.Max <= 0 {
+		return nil, errors.New("max must be positive")
+	}
+	if conf.Solver == nil {
+		return nil, errors.New("solver cannot be nil")
+	}
+	workers := conf.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	t := newTable(conf.Max)
+	solver := app.WithContext(conf.Solver)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pairs := make(chan [2]int)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pair := range pairs {
+				// Every pair writes its own part of the table.
+				if err := t.solvePair(ctx, solver, pair[0], pair[1]); err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+
+send:
+	for x := 1; x <= conf.Max; x++ {
+		for y := 1; y <= conf.Max; y++ {
+			select {
+			case pairs <- [2]int{x, y}:
+			case <-ctx.Done():
+				break send
+			}
+		}
+	}
+	close(pairs)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return nil, err
+	default:
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// solvePair solves every z for the capacities.
+func (t *Table) solvePair(ctx context.Context, solver app.ContextSolver, x, y int) error {
+
+	state := models.State{
+		X: models.Jug{Capacity: x},
+		Y: models.Jug{Capacity: y},
+	}
+	offset := t.offset(x, y)
+	for z := 0; z <= x || z <= y; z++ {
+		solution, err := solver.SolveContext(ctx, state, z)
+		if errors.Is(err, models.ErrNoSolution) {
+			t.steps[offset+z] = unsolvable
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("solving x=%d, y=%d, z=%d: %w", x, y, z, err)
+		}
+		t.steps[offset+z] = len(solution.Steps)
+	}
+	return nil
+}
+
+// Max returns the largest capacity in the table.
+func (t *Table) Max() int {
+	return t.max
+}
+
+// Lookup returns the Answer for measuring z with empty x and y jugs.
+//
+// An error wrapping ErrOutOfRange is returned if the capacities are not
+// between 1 and Max, or if z is not between 0 and the largest of them.
+func (t *Table) Lookup(x, y, z int) (Answer, error) {
+
+	if x < 1 || y < 1 || x > t.max || y > t.max || z < 0 || (z > x && z > y) {
+		return Answer{}, fmt.Errorf("%w: x=%d, y=%d, z=%d", ErrOutOfRange, x, y, z)
+	}
+	steps := t.steps[t.offset(x, y)+z]
+	if steps == unsolvable {
+		return Answer{}, nil
+	}
+	return Answer{Solvable: true, Steps: steps}, nil
+}
+
+// newTable allocates a table for capacities up to max, with every puzzle
+// unsolvable.
+func newTable(max int) *Table {
+
+	t := &Table{max: max, offsets: make([]int, max*max)}
+	size := 0
+	for x := 1; x <= max; x++ {
+		for y := 1; y <= max; y++ {
+			t.offsets[(x-1)*max+y-1] = size
+			size += maxInt(x, y) + 1
+		}
+	}
+	t.steps = make([]int, size)
+	for i := range t.steps {
+		t.steps[i] = unsolvable
+	}
+	return t
+}
+
+// offset returns the index of z = 0 for the capacities.
+func (t *Table) offset(x, y int) int {
+	return t.offsets[(x-1)*t.max+y-1]
+}
+
+func maxInt(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
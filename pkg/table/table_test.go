@@ -0,0 +1,142 @@
+package table_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nacho692/live-free-or-die-jugging/pkg/app"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/bfs"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/euclid"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/iterative"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/models"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/solvertest"
+	"github.com/nacho692/live-free-or-die-jugging/pkg/table"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+
+	tb, err := table.Generate(context.Background(), table.Configuration{
+		Max:     12,
+		Solver:  app.SolverFun(bfs.Solve),
+		Workers: 4,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 12, tb.Max())
+
+	for x := 1; x <= 12; x++ {
+		for y := 1; y <= 12; y++ {
+			for z := 0; z <= x || z <= y; z++ {
+				answer, err := tb.Lookup(x, y, z)
+				require.NoError(t, err)
+				assert.Equal(t, euclid.Solvable(x, y, z), answer.Solvable, "x=%d, y=%d, z=%d", x, y, z)
+
+				solution, err := bfs.Solve(solvertest.NewState(x, y), z)
+				if answer.Solvable {
+					require.NoError(t, err)
+					assert.Equal(t, len(solution.Steps), answer.Steps)
+				}
+			}
+		}
+	}
+
+	t.Run("out of range", func(t *testing.T) {
+		for _, puzzle := range [][3]int{{0, 5, 1}, {13, 5, 1}, {5, 13, 1}, {5, 3, 6}, {5, 3, -1}} {
+			_, err := tb.Lookup(puzzle[0], puzzle[1], puzzle[2])
+			assert.ErrorIs(t, err, table.ErrOutOfRange)
+		}
+	})
+}
+
+func TestGenerateErrors(t *testing.T) {
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, err := table.Generate(context.Background(), table.Configuration{Solver: app.SolverFun(bfs.Solve)})
+		assert.Error(t, err)
+
+		_, err = table.Generate(context.Background(), table.Configuration{Max: 3})
+		assert.Error(t, err)
+	})
+
+	t.Run("solver errors stop the generation", func(t *testing.T) {
+		failing := app.SolverFun(func(state models.State, z int) (models.Solution, error) {
+			if state.X.Capacity == 3 {
+				return models.Solution{}, errors.New("out of water")
+			}
+			return iterative.Solve(state, z)
+		})
+		_, err := table.Generate(context.Background(), table.Configuration{Max: 5, Solver: failing})
+		assert.ErrorContains(t, err, "out of water")
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := table.Generate(ctx, table.Configuration{Max: 5, Solver: app.SolverFun(iterative.Solve)})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestFormats(t *testing.T) {
+
+	tb, err := table.Generate(context.Background(), table.Configuration{
+		Max:    9,
+		Solver: app.ContextSolverFun(iterative.SolveContext),
+	})
+	require.NoError(t, err)
+
+	for _, format := range table.Formats {
+		t.Run(string(format), func(t *testing.T) {
+			output := &bytes.Buffer{}
+			require.NoError(t, tb.Write(output, format))
+
+			read, err := table.Read(bytes.NewReader(output.Bytes()), format)
+			require.NoError(t, err)
+			assert.Equal(t, tb, read)
+		})
+	}
+
+	t.Run("csv rows", func(t *testing.T) {
+		small, err := table.Generate(context.Background(), table.Configuration{
+			Max:    2,
+			Solver: app.SolverFun(bfs.Solve),
+		})
+		require.NoError(t, err)
+
+		output := &bytes.Buffer{}
+		require.NoError(t, small.Write(output, table.FormatCSV))
+		assert.Equal(t, "x,y,z,steps\n"+
+			"1,1,0,0\n1,1,1,1\n"+
+			"1,2,0,0\n1,2,1,1\n1,2,2,1\n"+
+			"2,1,0,0\n2,1,1,1\n2,1,2,1\n"+
+			"2,2,0,0\n2,2,2,1\n", output.String())
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		for _, input := range []struct {
+			format table.Format
+			data   string
+		}{
+			{table.FormatCSV, ""},
+			{table.FormatCSV, "x,y,z,steps\n"},
+			{table.FormatCSV, "x,y,z,steps\n1,1,2,1\n"},
+			{table.FormatCSV, "x,y,z,steps\n1,a,0,0\n"},
+			{table.FormatBinary, "WJT"},
+			{table.FormatBinary, "WJT\x01\x01\x01"},
+			{table.FormatBinary, "WJT\x01\x01\x01\x02\x01"},
+			{table.FormatBinary, "WJT\x01\x00"},
+			{"yaml", ""},
+		} {
+			_, err := table.Read(strings.NewReader(input.data), input.format)
+			assert.Error(t, err, "%s %q", input.format, input.data)
+		}
+
+		_, err := table.Read(strings.NewReader("WJT\x01\x01\x01\x02"), table.FormatBinary)
+		assert.NoError(t, err, "a single 1 gallon jug")
+	})
+}